@@ -1,12 +1,15 @@
 package main
 
 import (
+	"context"
 	"log"
 
 	"github.com/injunweb/backend-server/internal/api"
 	"github.com/injunweb/backend-server/internal/config"
+	"github.com/injunweb/backend-server/internal/services"
 	"github.com/injunweb/backend-server/pkg/database"
 	"github.com/injunweb/backend-server/pkg/kubernetes"
+	"github.com/injunweb/backend-server/pkg/signing"
 	"github.com/injunweb/backend-server/pkg/vault"
 
 	"github.com/gin-contrib/cors"
@@ -31,6 +34,11 @@ func main() {
 		log.Fatalf("Failed to initialize Vault: %v", err)
 	}
 
+	err = signing.Init()
+	if err != nil {
+		log.Fatalf("Failed to initialize JWT signing keys: %v", err)
+	}
+
 	router := gin.Default()
 
 	router.Use(cors.New(cors.Config{
@@ -44,7 +52,9 @@ func main() {
 		AllowCredentials: true,
 	}))
 
-	api.SetupRoutes(router)
+	jobService := services.NewJobService(database.DB)
+	api.SetupRoutes(router, jobService)
+	jobService.Start(context.Background())
 
 	if err := router.Run(":" + config.AppConfig.Port); err != nil {
 		log.Fatalf("Failed to start server: %v", err)
@@ -5,20 +5,24 @@ import (
 	"strconv"
 
 	"github.com/injunweb/backend-server/internal/services"
+	"github.com/injunweb/backend-server/pkg/errors"
 
 	"github.com/gin-gonic/gin"
 )
 
 type AdminHandler struct {
-	adminService *services.AdminService
+	adminService     *services.AdminService
+	reconcileService *services.ReconcileService
 }
 
-func NewAdminHandler(adminService *services.AdminService) *AdminHandler {
-	return &AdminHandler{adminService: adminService}
+func NewAdminHandler(adminService *services.AdminService, reconcileService *services.ReconcileService) *AdminHandler {
+	return &AdminHandler{adminService: adminService, reconcileService: reconcileService}
 }
 
 func (h *AdminHandler) GetUsersByAdmin(c *gin.Context) {
-	response, err := h.adminService.GetUsersByAdmin()
+	actorId, _ := c.Get("user_id")
+
+	response, err := h.adminService.GetUsersByAdmin(actorId.(uint))
 	if err != nil {
 		c.Error(err)
 		return
@@ -28,9 +32,75 @@ func (h *AdminHandler) GetUsersByAdmin(c *gin.Context) {
 }
 
 func (h *AdminHandler) GetUserByAdmin(c *gin.Context) {
+	actorId, _ := c.Get("user_id")
 	userId, _ := strconv.ParseUint(c.Param("userId"), 10, 32)
 
-	response, err := h.adminService.GetUserByAdmin(uint(userId))
+	response, err := h.adminService.GetUserByAdmin(actorId.(uint), uint(userId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) UnlockUserByAdmin(c *gin.Context) {
+	actorId, _ := c.Get("user_id")
+	userId, _ := strconv.ParseUint(c.Param("userId"), 10, 32)
+
+	response, err := h.adminService.UnlockUserByAdmin(actorId.(uint), uint(userId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) UpdateUserRoleByAdmin(c *gin.Context) {
+	actorId, _ := c.Get("user_id")
+	userId, _ := strconv.ParseUint(c.Param("userId"), 10, 32)
+
+	var req services.UpdateUserRoleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.adminService.UpdateUserRoleByAdmin(actorId.(uint), uint(userId), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) GetAuditLogsByAdmin(c *gin.Context) {
+	actorId, _ := c.Get("user_id")
+	targetId, _ := strconv.ParseUint(c.Query("target_id"), 10, 32)
+	limit, _ := strconv.Atoi(c.Query("limit"))
+
+	response, err := h.adminService.GetAuditLogsByAdmin(actorId.(uint), c.Query("target_type"), uint(targetId), limit)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) UpdateMaxReplicasByAdmin(c *gin.Context) {
+	actorId, _ := c.Get("user_id")
+	userId, _ := strconv.ParseUint(c.Param("userId"), 10, 32)
+
+	var req services.UpdateMaxReplicasRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.adminService.UpdateMaxReplicasByAdmin(actorId.(uint), uint(userId), req)
 	if err != nil {
 		c.Error(err)
 		return
@@ -40,9 +110,10 @@ func (h *AdminHandler) GetUserByAdmin(c *gin.Context) {
 }
 
 func (h *AdminHandler) GetApplicationsByAdmin(c *gin.Context) {
+	actorId, _ := c.Get("user_id")
 	userId, _ := strconv.ParseUint(c.Param("userId"), 10, 32)
 
-	response, err := h.adminService.GetApplicationsByAdmin(uint(userId))
+	response, err := h.adminService.GetApplicationsByAdmin(actorId.(uint), uint(userId))
 	if err != nil {
 		c.Error(err)
 		return
@@ -52,7 +123,9 @@ func (h *AdminHandler) GetApplicationsByAdmin(c *gin.Context) {
 }
 
 func (h *AdminHandler) GetAllApplicationsByAdmin(c *gin.Context) {
-	response, err := h.adminService.GetAllApplicationsByAdmin()
+	actorId, _ := c.Get("user_id")
+
+	response, err := h.adminService.GetAllApplicationsByAdmin(actorId.(uint))
 	if err != nil {
 		c.Error(err)
 		return
@@ -62,21 +135,42 @@ func (h *AdminHandler) GetAllApplicationsByAdmin(c *gin.Context) {
 }
 
 func (h *AdminHandler) ApproveApplicationByAdmin(c *gin.Context) {
+	actorId, _ := c.Get("user_id")
 	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
 
-	response, err := h.adminService.ApproveApplicationByAdmin(uint(appId))
+	response, err := h.adminService.ApproveApplicationByAdmin(actorId.(uint), uint(appId))
 	if err != nil {
 		c.Error(err)
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusAccepted, response)
 }
 
 func (h *AdminHandler) CancelApproveApplicationByAdmin(c *gin.Context) {
+	actorId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+
+	response, err := h.adminService.CancelApproveApplicationByAdmin(actorId.(uint), uint(appId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response)
+}
+
+func (h *AdminHandler) RejectApplicationByAdmin(c *gin.Context) {
+	actorId, _ := c.Get("user_id")
 	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
 
-	response, err := h.adminService.CancelApproveApplicationByAdmin(uint(appId))
+	var req services.ApplicationStatusReasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.adminService.RejectApplicationByAdmin(actorId.(uint), uint(appId), req)
 	if err != nil {
 		c.Error(err)
 		return
@@ -85,7 +179,40 @@ func (h *AdminHandler) CancelApproveApplicationByAdmin(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+func (h *AdminHandler) SuspendApplicationByAdmin(c *gin.Context) {
+	actorId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+
+	var req services.ApplicationStatusReasonRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.adminService.SuspendApplicationByAdmin(actorId.(uint), uint(appId), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response)
+}
+
+func (h *AdminHandler) ResumeApplicationByAdmin(c *gin.Context) {
+	actorId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+
+	response, err := h.adminService.ResumeApplicationByAdmin(actorId.(uint), uint(appId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response)
+}
+
 func (h *AdminHandler) UpdatePrimaryHostnameByAdmin(c *gin.Context) {
+	actorId, _ := c.Get("user_id")
 	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
 
 	var req services.UpdateCustomHostnameRequest
@@ -94,7 +221,45 @@ func (h *AdminHandler) UpdatePrimaryHostnameByAdmin(c *gin.Context) {
 		return
 	}
 
-	response, err := h.adminService.UpdatePrimaryHostnameByAdmin(uint(appId), req)
+	response, err := h.adminService.UpdatePrimaryHostnameByAdmin(actorId.(uint), uint(appId), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) UpdateApplicationQuotaByAdmin(c *gin.Context) {
+	actorId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+
+	var req services.UpdateApplicationQuotaRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.adminService.UpdateApplicationQuotaByAdmin(actorId.(uint), uint(appId), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) TransferApplicationByAdmin(c *gin.Context) {
+	actorId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+
+	var req services.TransferApplicationByAdminRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.adminService.TransferApplicationByAdmin(actorId.(uint), uint(appId), req)
 	if err != nil {
 		c.Error(err)
 		return
@@ -104,9 +269,22 @@ func (h *AdminHandler) UpdatePrimaryHostnameByAdmin(c *gin.Context) {
 }
 
 func (h *AdminHandler) GetApplicationByAdmin(c *gin.Context) {
+	actorId, _ := c.Get("user_id")
 	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
 
-	response, err := h.adminService.GetApplicationByAdmin(uint(appId))
+	response, err := h.adminService.GetApplicationByAdmin(actorId.(uint), uint(appId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AdminHandler) GetReconcileReportByAdmin(c *gin.Context) {
+	actorId, _ := c.Get("user_id")
+
+	response, err := h.reconcileService.GetReconcileReportByAdmin(actorId.(uint))
 	if err != nil {
 		c.Error(err)
 		return
@@ -114,3 +292,15 @@ func (h *AdminHandler) GetApplicationByAdmin(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+func (h *AdminHandler) RepairReconcileDriftByAdmin(c *gin.Context) {
+	actorId, _ := c.Get("user_id")
+
+	response, err := h.reconcileService.RepairReconcileDriftByAdmin(actorId.(uint))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response)
+}
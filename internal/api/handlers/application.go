@@ -1,8 +1,10 @@
 package handlers
 
 import (
+	"io"
 	"net/http"
 	"strconv"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/injunweb/backend-server/internal/services"
@@ -60,6 +62,171 @@ func (h *ApplicationHandler) GetApplication(c *gin.Context) {
 	c.JSON(http.StatusOK, response)
 }
 
+func (h *ApplicationHandler) UpdateApplication(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+
+	var request services.UpdateApplicationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.applicationService.UpdateApplication(userId.(uint), uint(appId), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ApplicationHandler) GetApplicationStatus(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+
+	response, err := h.applicationService.GetApplicationStatus(userId.(uint), uint(appId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ApplicationHandler) GetApplicationEvents(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+
+	response, err := h.applicationService.GetApplicationEvents(userId.(uint), uint(appId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+const logStreamKeepAlive = 15 * time.Second
+
+func (h *ApplicationHandler) StreamApplicationLogs(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+
+	var request services.GetApplicationLogsRequest
+	if err := c.ShouldBindQuery(&request); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	stream, err := h.applicationService.OpenLogStream(c.Request.Context(), userId.(uint), uint(appId), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+	defer stream.Close()
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+
+	keepAlive := time.NewTicker(logStreamKeepAlive)
+	defer keepAlive.Stop()
+
+	c.Stream(func(w io.Writer) bool {
+		select {
+		case line, ok := <-stream.Lines:
+			if !ok {
+				c.SSEvent("end", gin.H{})
+				return false
+			}
+			if line.Error != "" {
+				c.SSEvent("error", line)
+			} else {
+				c.SSEvent("log", line)
+			}
+			return true
+		case <-keepAlive.C:
+			c.SSEvent("ping", gin.H{})
+			return true
+		case <-c.Request.Context().Done():
+			return false
+		}
+	})
+}
+
+func (h *ApplicationHandler) GetApplicationQuota(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+
+	response, err := h.applicationService.GetApplicationQuota(userId.(uint), uint(appId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ApplicationHandler) RestartApplication(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+
+	response, err := h.applicationService.RestartApplication(userId.(uint), uint(appId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ApplicationHandler) ScaleApplication(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+
+	var request services.ScaleApplicationRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.applicationService.ScaleApplication(userId.(uint), uint(appId), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ApplicationHandler) StopApplication(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+
+	response, err := h.applicationService.StopApplication(userId.(uint), uint(appId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ApplicationHandler) StartApplication(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+
+	response, err := h.applicationService.StartApplication(userId.(uint), uint(appId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
 func (h *ApplicationHandler) DeleteApplication(c *gin.Context) {
 	userId, _ := c.Get("user_id")
 	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
@@ -70,7 +237,7 @@ func (h *ApplicationHandler) DeleteApplication(c *gin.Context) {
 		return
 	}
 
-	c.JSON(http.StatusOK, response)
+	c.JSON(http.StatusAccepted, response)
 }
 
 func (h *ApplicationHandler) AddExtralHostname(c *gin.Context) {
@@ -142,3 +309,139 @@ func (h *ApplicationHandler) UpdateEnvironment(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+func (h *ApplicationHandler) GetCollaborators(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+
+	response, err := h.applicationService.GetCollaborators(userId.(uint), uint(appId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ApplicationHandler) AddCollaborator(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+
+	var request services.AddCollaboratorRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.applicationService.AddCollaborator(userId.(uint), uint(appId), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *ApplicationHandler) UpdateCollaborator(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+	collaboratorUserId, _ := strconv.ParseUint(c.Param("userId"), 10, 32)
+
+	var request services.UpdateCollaboratorRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.applicationService.UpdateCollaborator(userId.(uint), uint(appId), uint(collaboratorUserId), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ApplicationHandler) RemoveCollaborator(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+	collaboratorUserId, _ := strconv.ParseUint(c.Param("userId"), 10, 32)
+
+	response, err := h.applicationService.RemoveCollaborator(userId.(uint), uint(appId), uint(collaboratorUserId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ApplicationHandler) RequestApplicationTransfer(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+
+	var request services.RequestApplicationTransferRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.applicationService.RequestApplicationTransfer(userId.(uint), uint(appId), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *ApplicationHandler) CancelApplicationTransfer(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	appId, _ := strconv.ParseUint(c.Param("appId"), 10, 32)
+
+	response, err := h.applicationService.CancelApplicationTransfer(userId.(uint), uint(appId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ApplicationHandler) GetApplicationTransfers(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+
+	response, err := h.applicationService.GetApplicationTransfers(userId.(uint))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ApplicationHandler) AcceptApplicationTransfer(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	transferId, _ := strconv.ParseUint(c.Param("transferId"), 10, 32)
+
+	response, err := h.applicationService.AcceptApplicationTransfer(userId.(uint), uint(transferId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *ApplicationHandler) DeclineApplicationTransfer(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	transferId, _ := strconv.ParseUint(c.Param("transferId"), 10, 32)
+
+	response, err := h.applicationService.DeclineApplicationTransfer(userId.(uint), uint(transferId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strconv"
 
 	"github.com/gin-gonic/gin"
 	"github.com/injunweb/backend-server/internal/services"
@@ -23,7 +24,7 @@ func (h *AuthHandler) Login(c *gin.Context) {
 		return
 	}
 
-	response, err := h.authService.Login(loginRequest)
+	response, err := h.authService.Login(loginRequest, c.ClientIP(), c.Request.UserAgent())
 	if err != nil {
 		c.Error(err)
 		return
@@ -47,3 +48,339 @@ func (h *AuthHandler) Register(c *gin.Context) {
 
 	c.JSON(http.StatusCreated, response)
 }
+
+func (h *AuthHandler) RefreshToken(c *gin.Context) {
+	var request services.RefreshTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.authService.RefreshToken(request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) Logout(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	sessionId, _ := c.Get("session_id")
+
+	response, err := h.authService.Logout(userId.(uint), sessionId.(uint))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) LogoutAll(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+
+	response, err := h.authService.LogoutAll(userId.(uint))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) RequestPasswordReset(c *gin.Context) {
+	var request services.RequestPasswordResetRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.authService.RequestPasswordReset(request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) ConfirmPasswordReset(c *gin.Context) {
+	var request services.ConfirmPasswordResetRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.authService.ConfirmPasswordReset(request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) VerifyEmail(c *gin.Context) {
+	var request services.VerifyEmailRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.authService.VerifyEmail(request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) ChangePassword(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	sessionId, _ := c.Get("session_id")
+
+	var request services.ChangePasswordRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.authService.ChangePassword(userId.(uint), sessionId.(uint), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) LoginTwoFactor(c *gin.Context) {
+	var request services.LoginTwoFactorRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.authService.LoginTwoFactor(request, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) SetupTwoFactor(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+
+	response, err := h.authService.SetupTwoFactor(userId.(uint))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) ConfirmTwoFactor(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+
+	var request services.ConfirmTwoFactorRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.authService.ConfirmTwoFactor(userId.(uint), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) RegenerateRecoveryCodes(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+
+	var request services.RegenerateRecoveryCodesRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.authService.RegenerateRecoveryCodes(userId.(uint), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) DisableTwoFactor(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	sessionId, _ := c.Get("session_id")
+
+	var request services.DisableTwoFactorRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.authService.DisableTwoFactor(userId.(uint), sessionId.(uint), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) GithubAuthorize(c *gin.Context) {
+	response, err := h.authService.GithubAuthorize()
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setGithubNonceCookie(c, response.Nonce)
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) GithubLogin(c *gin.Context) {
+	var request services.GithubCallbackRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	request.Nonce, _ = c.Cookie(githubNonceCookie)
+	setGithubNonceCookie(c, "")
+
+	response, err := h.authService.GithubLogin(request, c.ClientIP(), c.Request.UserAgent())
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) GithubLinkAuthorize(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+
+	response, err := h.authService.GithubLinkAuthorize(userId.(uint))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	setGithubNonceCookie(c, response.Nonce)
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) GithubLink(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+
+	var request services.GithubCallbackRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	request.Nonce, _ = c.Cookie(githubNonceCookie)
+	setGithubNonceCookie(c, "")
+
+	response, err := h.authService.GithubLink(userId.(uint), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) GithubUnlink(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+
+	response, err := h.authService.GithubUnlink(userId.(uint))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) GetPersonalAccessTokens(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+
+	response, err := h.authService.GetPersonalAccessTokens(userId.(uint))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) CreatePersonalAccessToken(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+
+	var request services.CreatePersonalAccessTokenRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.authService.CreatePersonalAccessToken(userId.(uint), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *AuthHandler) DeletePersonalAccessToken(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	tokenId, err := strconv.ParseUint(c.Param("tokenId"), 10, 32)
+	if err != nil {
+		c.Error(errors.BadRequest("invalid token ID"))
+		return
+	}
+
+	response, customErr := h.authService.DeletePersonalAccessToken(userId.(uint), uint(tokenId))
+	if customErr != nil {
+		c.Error(customErr)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *AuthHandler) GetJWKS(c *gin.Context) {
+	c.Header("Cache-Control", "public, max-age=300")
+	c.JSON(http.StatusOK, h.authService.GetJWKS())
+}
+
+const githubNonceCookie = "github_oauth_nonce"
+
+// setGithubNonceCookie binds a GitHub OAuth state to the browser that started
+// the flow; an empty value clears the cookie.
+func setGithubNonceCookie(c *gin.Context, nonce string) {
+	maxAge := 600
+	if nonce == "" {
+		maxAge = -1
+	}
+
+	secure := c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https"
+	c.SetSameSite(http.SameSiteLaxMode)
+	c.SetCookie(githubNonceCookie, nonce, maxAge, "/", "", secure, true)
+}
@@ -0,0 +1,31 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/injunweb/backend-server/internal/services"
+
+	"github.com/gin-gonic/gin"
+)
+
+type JobHandler struct {
+	jobService *services.JobService
+}
+
+func NewJobHandler(jobService *services.JobService) *JobHandler {
+	return &JobHandler{jobService: jobService}
+}
+
+func (h *JobHandler) GetJob(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	jobId, _ := strconv.ParseUint(c.Param("jobId"), 10, 32)
+
+	response, err := h.jobService.GetJob(userId.(uint), uint(jobId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
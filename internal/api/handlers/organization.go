@@ -0,0 +1,173 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/injunweb/backend-server/internal/services"
+	"github.com/injunweb/backend-server/pkg/errors"
+
+	"github.com/gin-gonic/gin"
+)
+
+type OrganizationHandler struct {
+	organizationService *services.OrganizationService
+}
+
+func NewOrganizationHandler(organizationService *services.OrganizationService) *OrganizationHandler {
+	return &OrganizationHandler{organizationService: organizationService}
+}
+
+func (h *OrganizationHandler) GetOrganizations(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+
+	response, err := h.organizationService.GetOrganizations(userId.(uint))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *OrganizationHandler) CreateOrganization(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+
+	var req services.CreateOrganizationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.organizationService.CreateOrganization(userId.(uint), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *OrganizationHandler) GetOrganization(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	orgId, _ := strconv.ParseUint(c.Param("orgId"), 10, 32)
+
+	response, err := h.organizationService.GetOrganization(userId.(uint), uint(orgId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *OrganizationHandler) DeleteOrganization(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	orgId, _ := strconv.ParseUint(c.Param("orgId"), 10, 32)
+
+	response, err := h.organizationService.DeleteOrganization(userId.(uint), uint(orgId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *OrganizationHandler) UpdateOrganizationMember(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	orgId, _ := strconv.ParseUint(c.Param("orgId"), 10, 32)
+	memberUserId, _ := strconv.ParseUint(c.Param("userId"), 10, 32)
+
+	var req services.UpdateOrganizationMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.organizationService.UpdateOrganizationMember(userId.(uint), uint(orgId), uint(memberUserId), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *OrganizationHandler) RemoveOrganizationMember(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	orgId, _ := strconv.ParseUint(c.Param("orgId"), 10, 32)
+	memberUserId, _ := strconv.ParseUint(c.Param("userId"), 10, 32)
+
+	response, err := h.organizationService.RemoveOrganizationMember(userId.(uint), uint(orgId), uint(memberUserId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *OrganizationHandler) GetOrganizationInvitations(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	orgId, _ := strconv.ParseUint(c.Param("orgId"), 10, 32)
+
+	response, err := h.organizationService.GetOrganizationInvitations(userId.(uint), uint(orgId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *OrganizationHandler) InviteOrganizationMember(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	orgId, _ := strconv.ParseUint(c.Param("orgId"), 10, 32)
+
+	var req services.InviteOrganizationMemberRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.organizationService.InviteOrganizationMember(userId.(uint), uint(orgId), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusCreated, response)
+}
+
+func (h *OrganizationHandler) RevokeOrganizationInvitation(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+	orgId, _ := strconv.ParseUint(c.Param("orgId"), 10, 32)
+	invitationId, _ := strconv.ParseUint(c.Param("invitationId"), 10, 32)
+
+	response, err := h.organizationService.RevokeOrganizationInvitation(userId.(uint), uint(orgId), uint(invitationId))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *OrganizationHandler) AcceptOrganizationInvitation(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+
+	var req services.AcceptOrganizationInvitationRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.organizationService.AcceptOrganizationInvitation(userId.(uint), req)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
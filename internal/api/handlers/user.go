@@ -1,6 +1,7 @@
 package handlers
 
 import (
+	"fmt"
 	"net/http"
 
 	"github.com/gin-gonic/gin"
@@ -9,11 +10,12 @@ import (
 )
 
 type UserHandler struct {
-	userService *services.UserService
+	userService    *services.UserService
+	accountService *services.AccountService
 }
 
-func NewUserHandler(userService *services.UserService) *UserHandler {
-	return &UserHandler{userService: userService}
+func NewUserHandler(userService *services.UserService, accountService *services.AccountService) *UserHandler {
+	return &UserHandler{userService: userService, accountService: accountService}
 }
 
 func (h *UserHandler) GetUser(c *gin.Context) {
@@ -45,3 +47,46 @@ func (h *UserHandler) UpdateUser(c *gin.Context) {
 
 	c.JSON(http.StatusOK, response)
 }
+
+func (h *UserHandler) ResendVerificationEmail(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+
+	response, err := h.userService.ResendVerificationEmail(userId.(uint))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *UserHandler) ExportAccount(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+
+	response, err := h.accountService.ExportAccount(userId.(uint))
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.Header("Content-Disposition", fmt.Sprintf("attachment; filename=injunweb-account-%d.json", userId.(uint)))
+	c.JSON(http.StatusOK, response)
+}
+
+func (h *UserHandler) DeleteAccount(c *gin.Context) {
+	userId, _ := c.Get("user_id")
+
+	var request services.DeleteAccountRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.Error(errors.BadRequest("invalid request format"))
+		return
+	}
+
+	response, err := h.accountService.DeleteAccount(userId.(uint), request)
+	if err != nil {
+		c.Error(err)
+		return
+	}
+
+	c.JSON(http.StatusAccepted, response)
+}
@@ -3,59 +3,163 @@ package api
 import (
 	"github.com/injunweb/backend-server/internal/api/handlers"
 	"github.com/injunweb/backend-server/internal/middleware"
+	"github.com/injunweb/backend-server/internal/models"
 	"github.com/injunweb/backend-server/internal/services"
 	"github.com/injunweb/backend-server/pkg/database"
 
 	"github.com/gin-gonic/gin"
 )
 
-func SetupRoutes(router *gin.Engine) {
+func SetupRoutes(router *gin.Engine, jobService *services.JobService) {
 	userService := services.NewUserService(database.DB)
 	notificationService := services.NewNotificationService(database.DB, userService)
-	authService := services.NewAuthService(database.DB, notificationService)
-	appService := services.NewApplicationService(database.DB, notificationService)
-	adminService := services.NewAdminService(database.DB, notificationService)
+	loginLimiter := services.NewLoginLimiter(database.DB)
+	authService := services.NewAuthService(database.DB, notificationService, loginLimiter)
+	provisioningService := services.NewProvisioningService(database.DB, notificationService, jobService)
+	appService := services.NewApplicationService(database.DB, notificationService, provisioningService)
+	adminService := services.NewAdminService(database.DB, notificationService, loginLimiter, provisioningService)
+	organizationService := services.NewOrganizationService(database.DB, notificationService)
+	reconcileService := services.NewReconcileService(database.DB, notificationService, jobService)
+	// The monitoring service only runs as a scheduled job.
+	services.NewMonitoringService(database.DB, notificationService, jobService)
+	accountService := services.NewAccountService(database.DB, notificationService, provisioningService, jobService)
 
 	authHandler := handlers.NewAuthHandler(authService)
-	userHandler := handlers.NewUserHandler(userService)
+	userHandler := handlers.NewUserHandler(userService, accountService)
 	appHandler := handlers.NewApplicationHandler(appService)
 	notificationHandler := handlers.NewNotificationHandler(notificationService, userService)
-	adminHandler := handlers.NewAdminHandler(adminService)
+	adminHandler := handlers.NewAdminHandler(adminService, reconcileService)
+	organizationHandler := handlers.NewOrganizationHandler(organizationService)
+	jobHandler := handlers.NewJobHandler(jobService)
 
 	router.Use(middleware.ErrorMiddleware())
 
+	authMiddleware := middleware.AuthMiddleware(authService)
+	sessionOnly := middleware.SessionOnly()
+
+	router.GET("/.well-known/jwks.json", authHandler.GetJWKS)
+
 	auth := router.Group("/auth")
 	{
 		auth.POST("/login", authHandler.Login)
+		auth.POST("/login/2fa", authHandler.LoginTwoFactor)
 		auth.POST("/register", authHandler.Register)
+		auth.POST("/refresh", authHandler.RefreshToken)
+		auth.POST("/logout", authMiddleware, sessionOnly, authHandler.Logout)
+		auth.POST("/logout-all", authMiddleware, sessionOnly, authHandler.LogoutAll)
+		auth.POST("/password-reset/request", authHandler.RequestPasswordReset)
+		auth.POST("/password-reset/confirm", authHandler.ConfirmPasswordReset)
+		auth.POST("/verify-email", authHandler.VerifyEmail)
+
+		githubAuth := auth.Group("/github")
+		{
+			githubAuth.GET("/authorize", authHandler.GithubAuthorize)
+			githubAuth.POST("/callback", authHandler.GithubLogin)
+		}
 	}
 
 	users := router.Group("/users")
-	users.Use(middleware.AuthMiddleware())
+	users.Use(authMiddleware)
 	{
-		users.GET("", userHandler.GetUser)
-		users.PATCH("", userHandler.UpdateUser)
+		users.GET("", middleware.RequireScopes(models.ScopeUserRead, ""), userHandler.GetUser)
+		users.PATCH("", sessionOnly, userHandler.UpdateUser)
+		users.DELETE("", sessionOnly, userHandler.DeleteAccount)
+		users.GET("/export", sessionOnly, userHandler.ExportAccount)
+		users.PATCH("/password", sessionOnly, authHandler.ChangePassword)
+		users.POST("/verify-email/resend", sessionOnly, userHandler.ResendVerificationEmail)
+
+		tokens := users.Group("/tokens")
+		tokens.Use(sessionOnly)
+		{
+			tokens.GET("", authHandler.GetPersonalAccessTokens)
+			tokens.POST("", authHandler.CreatePersonalAccessToken)
+			tokens.DELETE("/:tokenId", authHandler.DeletePersonalAccessToken)
+		}
+
+		twoFactor := users.Group("/2fa")
+		twoFactor.Use(sessionOnly)
+		{
+			twoFactor.POST("/setup", authHandler.SetupTwoFactor)
+			twoFactor.POST("/confirm", authHandler.ConfirmTwoFactor)
+			twoFactor.POST("/recovery-codes", authHandler.RegenerateRecoveryCodes)
+			twoFactor.DELETE("", authHandler.DisableTwoFactor)
+		}
+
+		githubLink := users.Group("/github")
+		githubLink.Use(sessionOnly)
+		{
+			githubLink.GET("/authorize", authHandler.GithubLinkAuthorize)
+			githubLink.POST("/callback", authHandler.GithubLink)
+			githubLink.DELETE("", authHandler.GithubUnlink)
+		}
 	}
 
 	applications := router.Group("/applications")
-	applications.Use(middleware.AuthMiddleware())
+	applications.Use(authMiddleware, middleware.RequireScopes(models.ScopeAppsRead, models.ScopeAppsWrite))
 	{
 		applications.POST("", appHandler.SubmitApplication)
 		applications.GET("", appHandler.GetApplications)
+		applications.GET("/transfers", appHandler.GetApplicationTransfers)
+		applications.POST("/transfers/:transferId/accept", sessionOnly, appHandler.AcceptApplicationTransfer)
+		applications.POST("/transfers/:transferId/decline", sessionOnly, appHandler.DeclineApplicationTransfer)
 		applications.GET("/:appId", appHandler.GetApplication)
-		applications.DELETE("/:appId", appHandler.DeleteApplication)
+		applications.GET("/:appId/status", appHandler.GetApplicationStatus)
+		applications.GET("/:appId/logs", appHandler.StreamApplicationLogs)
+		applications.GET("/:appId/events", appHandler.GetApplicationEvents)
+		applications.GET("/:appId/quota", appHandler.GetApplicationQuota)
+		applications.POST("/:appId/restart", appHandler.RestartApplication)
+		applications.POST("/:appId/scale", appHandler.ScaleApplication)
+		applications.POST("/:appId/stop", appHandler.StopApplication)
+		applications.POST("/:appId/start", appHandler.StartApplication)
+		applications.PATCH("/:appId", appHandler.UpdateApplication)
+		applications.DELETE("/:appId", sessionOnly, appHandler.DeleteApplication)
 		applications.POST("/:appId/extra-hostnames", appHandler.AddExtralHostname)
 		applications.DELETE("/:appId/extra-hostnames", appHandler.DeleteExtraHostname)
+		applications.POST("/:appId/transfer", sessionOnly, appHandler.RequestApplicationTransfer)
+		applications.DELETE("/:appId/transfer", sessionOnly, appHandler.CancelApplicationTransfer)
 
-		environments := applications.Group("/:appId/environments")
+		collaborators := applications.Group("/:appId/collaborators")
+		collaborators.Use(sessionOnly)
 		{
-			environments.GET("", appHandler.GetEnvironments)
-			environments.POST("", appHandler.UpdateEnvironment)
+			collaborators.GET("", appHandler.GetCollaborators)
+			collaborators.POST("", appHandler.AddCollaborator)
+			collaborators.PATCH("/:userId", appHandler.UpdateCollaborator)
+			collaborators.DELETE("/:userId", appHandler.RemoveCollaborator)
 		}
 	}
 
+	// Environment variables are guarded by their own scopes only, so a token
+	// limited to env access does not also need the application scopes.
+	environments := router.Group("/applications/:appId/environments")
+	environments.Use(authMiddleware, middleware.RequireScopes(models.ScopeEnvRead, models.ScopeEnvWrite))
+	{
+		environments.GET("", appHandler.GetEnvironments)
+		environments.POST("", appHandler.UpdateEnvironment)
+	}
+
+	organizations := router.Group("/organizations")
+	organizations.Use(authMiddleware, sessionOnly)
+	{
+		organizations.GET("", organizationHandler.GetOrganizations)
+		organizations.POST("", organizationHandler.CreateOrganization)
+		organizations.POST("/invitations/accept", organizationHandler.AcceptOrganizationInvitation)
+		organizations.GET("/:orgId", organizationHandler.GetOrganization)
+		organizations.DELETE("/:orgId", organizationHandler.DeleteOrganization)
+		organizations.PATCH("/:orgId/members/:userId", organizationHandler.UpdateOrganizationMember)
+		organizations.DELETE("/:orgId/members/:userId", organizationHandler.RemoveOrganizationMember)
+		organizations.GET("/:orgId/invitations", organizationHandler.GetOrganizationInvitations)
+		organizations.POST("/:orgId/invitations", organizationHandler.InviteOrganizationMember)
+		organizations.DELETE("/:orgId/invitations/:invitationId", organizationHandler.RevokeOrganizationInvitation)
+	}
+
+	jobs := router.Group("/jobs")
+	jobs.Use(authMiddleware, middleware.RequireScopes(models.ScopeAppsRead, ""))
+	{
+		jobs.GET("/:jobId", jobHandler.GetJob)
+	}
+
 	notifications := router.Group("/notifications")
-	notifications.Use(middleware.AuthMiddleware())
+	notifications.Use(authMiddleware, middleware.RequireScopes(models.ScopeNotificationsRead, models.ScopeNotificationsWrite))
 	{
 		notifications.GET("", notificationHandler.GetNotifications)
 		notifications.POST("/read", notificationHandler.MarkAllAsRead)
@@ -65,25 +169,40 @@ func SetupRoutes(router *gin.Engine) {
 	}
 
 	admin := router.Group("/admin")
-	admin.Use(middleware.AuthMiddleware(), middleware.AdminMiddleware())
+	admin.Use(authMiddleware, sessionOnly, middleware.StaffMiddleware(authService))
 	{
 		adminUsers := admin.Group("/users")
+		adminUsers.Use(middleware.RequirePermission(models.PermissionUsersRead))
 		{
 			adminUsers.GET("", adminHandler.GetUsersByAdmin)
 			adminUsers.GET("/:userId", adminHandler.GetUserByAdmin)
+			adminUsers.POST("/:userId/unlock", middleware.RequirePermission(models.PermissionUsersManage), adminHandler.UnlockUserByAdmin)
+			adminUsers.POST("/:userId/roles", middleware.RequirePermission(models.PermissionRolesManage), adminHandler.UpdateUserRoleByAdmin)
+			adminUsers.PATCH("/:userId/max-replicas", middleware.RequirePermission(models.PermissionUsersManage), adminHandler.UpdateMaxReplicasByAdmin)
 
 			adminApplications := adminUsers.Group("/:userId/applications")
+			adminApplications.Use(middleware.RequirePermission(models.PermissionApplicationsRead))
 			{
 				adminApplications.GET("", adminHandler.GetApplicationsByAdmin)
 			}
 		}
 
+		admin.GET("/audit-logs", middleware.RequirePermission(models.PermissionAuditRead), adminHandler.GetAuditLogsByAdmin)
+		admin.GET("/reconcile", middleware.RequirePermission(models.PermissionApplicationsRead), adminHandler.GetReconcileReportByAdmin)
+		admin.POST("/reconcile/repair", middleware.RequirePermission(models.PermissionApplicationsManage), adminHandler.RepairReconcileDriftByAdmin)
+
 		adminApplications := admin.Group("/applications")
+		adminApplications.Use(middleware.RequirePermission(models.PermissionApplicationsRead))
 		{
 			adminApplications.GET("", adminHandler.GetAllApplicationsByAdmin)
-			adminApplications.POST("/:appId/approve", adminHandler.ApproveApplicationByAdmin)
-			adminApplications.POST("/:appId/cancel-approve", adminHandler.CancelApproveApplicationByAdmin)
-			adminApplications.POST("/:appId/primary-hostname", adminHandler.UpdatePrimaryHostnameByAdmin)
+			adminApplications.POST("/:appId/approve", middleware.RequirePermission(models.PermissionApplicationsReview), adminHandler.ApproveApplicationByAdmin)
+			adminApplications.POST("/:appId/cancel-approve", middleware.RequirePermission(models.PermissionApplicationsManage), adminHandler.CancelApproveApplicationByAdmin)
+			adminApplications.POST("/:appId/reject", middleware.RequirePermission(models.PermissionApplicationsReview), adminHandler.RejectApplicationByAdmin)
+			adminApplications.POST("/:appId/suspend", middleware.RequirePermission(models.PermissionApplicationsManage), adminHandler.SuspendApplicationByAdmin)
+			adminApplications.POST("/:appId/resume", middleware.RequirePermission(models.PermissionApplicationsManage), adminHandler.ResumeApplicationByAdmin)
+			adminApplications.POST("/:appId/primary-hostname", middleware.RequirePermission(models.PermissionApplicationsManage), adminHandler.UpdatePrimaryHostnameByAdmin)
+			adminApplications.POST("/:appId/transfer", middleware.RequirePermission(models.PermissionApplicationsManage), adminHandler.TransferApplicationByAdmin)
+			adminApplications.PATCH("/:appId/quota", middleware.RequirePermission(models.PermissionApplicationsManage), adminHandler.UpdateApplicationQuotaByAdmin)
 			adminApplications.GET("/:appId", adminHandler.GetApplicationByAdmin)
 		}
 	}
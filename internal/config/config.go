@@ -4,7 +4,13 @@ import "os"
 
 type Config struct {
 	Port              string
+	DashboardURL      string
 	GithubToken       string
+	GithubOAuthID     string
+	GithubOAuthSecret string
+	GithubOAuthURL    string
+	GithubAPIURL      string
+	GithubRedirectURL string
 	VaultAddr         string
 	VaultToken        string
 	VaultKV           string
@@ -21,6 +27,12 @@ type Config struct {
 	SMTPPass          string
 	JWTSecret         string
 	JWTExpiryHours    string
+	JWTKeysDir        string
+	JWTActiveKeyID    string
+	RefreshTokenHours string
+	LoginLimiter      string
+	JobWorkers        string
+	ReconcileMinutes  string
 	VapidPrivateKey   string
 	VapidPublicKey    string
 	DBHost            string
@@ -36,7 +48,13 @@ var AppConfig Config
 func Load() {
 	AppConfig = Config{
 		Port:              os.Getenv("PORT"),
+		DashboardURL:      os.Getenv("DASHBOARD_URL"),
 		GithubToken:       os.Getenv("GITHUB_TOKEN"),
+		GithubOAuthID:     os.Getenv("GITHUB_OAUTH_CLIENT_ID"),
+		GithubOAuthSecret: os.Getenv("GITHUB_OAUTH_CLIENT_SECRET"),
+		GithubOAuthURL:    os.Getenv("GITHUB_OAUTH_URL"),
+		GithubAPIURL:      os.Getenv("GITHUB_API_URL"),
+		GithubRedirectURL: os.Getenv("GITHUB_OAUTH_REDIRECT_URL"),
 		VaultAddr:         os.Getenv("VAULT_ADDR"),
 		VaultToken:        os.Getenv("VAULT_TOKEN"),
 		VaultKV:           os.Getenv("VAULT_KV"),
@@ -53,6 +71,12 @@ func Load() {
 		SMTPPass:          os.Getenv("SMTP_PASS"),
 		JWTSecret:         os.Getenv("JWT_SECRET"),
 		JWTExpiryHours:    os.Getenv("JWT_EXPIRY_HOURS"),
+		JWTKeysDir:        os.Getenv("JWT_KEYS_DIR"),
+		JWTActiveKeyID:    os.Getenv("JWT_ACTIVE_KEY_ID"),
+		RefreshTokenHours: os.Getenv("REFRESH_TOKEN_HOURS"),
+		LoginLimiter:      os.Getenv("LOGIN_LIMITER_BACKEND"),
+		JobWorkers:        os.Getenv("JOB_WORKERS"),
+		ReconcileMinutes:  os.Getenv("RECONCILE_INTERVAL_MINUTES"),
 		VapidPrivateKey:   os.Getenv("VAPID_PRIVATE_KEY"),
 		VapidPublicKey:    os.Getenv("VAPID_PUBLIC_KEY"),
 		DBHost:            os.Getenv("DB_HOST"),
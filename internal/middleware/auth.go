@@ -1,16 +1,22 @@
 package middleware
 
 import (
-	"fmt"
 	"net/http"
+	"strings"
 
-	"github.com/injunweb/backend-server/internal/config"
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/internal/services"
+	"github.com/injunweb/backend-server/pkg/errors"
 
 	"github.com/gin-gonic/gin"
-	"github.com/golang-jwt/jwt"
 )
 
-func AuthMiddleware() gin.HandlerFunc {
+const (
+	AuthTypeSession = "session"
+	AuthTypeToken   = "token"
+)
+
+func AuthMiddleware(authService *services.AuthService) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		tokenString := c.GetHeader("Authorization")
 		if tokenString == "" {
@@ -27,20 +33,86 @@ func AuthMiddleware() gin.HandlerFunc {
 			return
 		}
 
-		token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-			}
-			return []byte(config.AppConfig.JWTSecret), nil
-		})
-
-		if claims, ok := token.Claims.(jwt.MapClaims); ok && token.Valid {
-			userId := uint(claims["user_id"].(float64))
-			isAdmin := claims["is_admin"].(bool)
-			c.Set("user_id", userId)
-			c.Set("is_admin", isAdmin)
+		var claims services.AccessTokenClaims
+		var err errors.CustomError
+		authType := AuthTypeSession
+		if strings.HasPrefix(tokenString, services.PersonalAccessTokenPrefix) {
+			authType = AuthTypeToken
+			claims, err = authService.ValidatePersonalAccessToken(tokenString)
 		} else {
-			c.JSON(http.StatusUnauthorized, gin.H{"error": err.Error()})
+			claims, err = authService.ValidateAccessToken(tokenString)
+		}
+
+		if err != nil {
+			c.JSON(http.StatusUnauthorized, gin.H{"error": err.GetMessage()})
+			c.Abort()
+			return
+		}
+
+		c.Set("user_id", claims.UserID)
+		c.Set("role", claims.Role)
+		c.Set("auth_type", authType)
+		c.Set("scopes", claims.Scopes)
+		if authType == AuthTypeSession {
+			c.Set("session_id", claims.SessionID)
+		}
+
+		c.Next()
+	}
+}
+
+func SessionOnly() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_type") != AuthTypeSession {
+			c.JSON(http.StatusForbidden, gin.H{"error": "This endpoint cannot be used with a personal access token"})
+			c.Abort()
+			return
+		}
+
+		c.Next()
+	}
+}
+
+// RequireScopes checks personal access token scopes, using readScope for safe
+// methods and writeScope for everything else. Session tokens carry every scope.
+func RequireScopes(readScope string, writeScope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if c.GetString("auth_type") != AuthTypeToken {
+			c.Next()
+			return
+		}
+
+		required := writeScope
+		if c.Request.Method == http.MethodGet || c.Request.Method == http.MethodHead {
+			required = readScope
+		}
+
+		scopes, _ := c.Get("scopes")
+		for _, scope := range scopes.([]string) {
+			if scope == required {
+				c.Next()
+				return
+			}
+		}
+
+		c.JSON(http.StatusForbidden, gin.H{"error": "Token is missing required scope: " + required})
+		c.Abort()
+	}
+}
+
+// StaffMiddleware admits any role with admin API access; individual routes then
+// narrow it down with RequirePermission.
+func StaffMiddleware(authService *services.AuthService) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if !models.IsStaffRole(c.GetString("role")) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Staff role required"})
+			c.Abort()
+			return
+		}
+
+		userId, _ := c.Get("user_id")
+		if !authService.IsTwoFactorEnabled(userId.(uint)) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Two-factor authentication must be enabled for staff accounts"})
 			c.Abort()
 			return
 		}
@@ -49,11 +121,10 @@ func AuthMiddleware() gin.HandlerFunc {
 	}
 }
 
-func AdminMiddleware() gin.HandlerFunc {
+func RequirePermission(permission string) gin.HandlerFunc {
 	return func(c *gin.Context) {
-		isAdmin, _ := c.Get("is_admin")
-		if !isAdmin.(bool) {
-			c.JSON(http.StatusForbidden, gin.H{"error": "Admin permission required"})
+		if !models.RoleHasPermission(c.GetString("role"), permission) {
+			c.JSON(http.StatusForbidden, gin.H{"error": "Missing required permission: " + permission})
 			c.Abort()
 			return
 		}
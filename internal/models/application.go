@@ -1,14 +1,37 @@
 package models
 
 import (
+	"fmt"
+
 	"gorm.io/gorm"
 )
 
 const (
-	ApplicationStatusPending  string = "Pending"
-	ApplicationStatusApproved string = "Approved"
+	ApplicationStatusPending      string = "Pending"
+	ApplicationStatusProvisioning string = "Provisioning"
+	ApplicationStatusApproved     string = "Approved"
+	ApplicationStatusFailed       string = "Failed"
+	ApplicationStatusRejected     string = "Rejected"
+	ApplicationStatusSuspended    string = "Suspended"
+	ApplicationStatusDeleting     string = "Deleting"
+	// ApplicationStatusDeprovisioning marks an approval being withdrawn while
+	// its resources are torn down.
+	ApplicationStatusDeprovisioning string = "Deprovisioning"
 )
 
+// applicationStatusTransitions is the single source of truth for the
+// application lifecycle; every status change goes through TransitionTo.
+var applicationStatusTransitions = map[string][]string{
+	ApplicationStatusPending:        {ApplicationStatusProvisioning, ApplicationStatusRejected, ApplicationStatusDeleting},
+	ApplicationStatusProvisioning:   {ApplicationStatusApproved, ApplicationStatusFailed},
+	ApplicationStatusApproved:       {ApplicationStatusSuspended, ApplicationStatusDeprovisioning, ApplicationStatusDeleting},
+	ApplicationStatusFailed:         {ApplicationStatusProvisioning, ApplicationStatusDeprovisioning, ApplicationStatusDeleting},
+	ApplicationStatusRejected:       {ApplicationStatusPending, ApplicationStatusDeleting},
+	ApplicationStatusSuspended:      {ApplicationStatusApproved, ApplicationStatusDeprovisioning, ApplicationStatusDeleting},
+	ApplicationStatusDeprovisioning: {ApplicationStatusPending, ApplicationStatusFailed},
+	ApplicationStatusDeleting:       {ApplicationStatusFailed},
+}
+
 type Application struct {
 	gorm.Model
 	Name            string           `gorm:"type:varchar(255);uniqueIndex;not null" json:"name"`
@@ -17,8 +40,47 @@ type Application struct {
 	Port            string           `gorm:"not null" json:"port"`
 	Description     string           `json:"description"`
 	Status          string           `gorm:"default:'Pending'" json:"status"`
+	StatusReason    string           `gorm:"type:varchar(1024)" json:"status_reason"`
 	OwnerID         uint             `gorm:"not null" json:"owner_id"`
 	Owner           User             `gorm:"foreignKey:OwnerID" json:"owner,omitempty"`
+	OrganizationID  *uint            `gorm:"index" json:"organization_id"`
 	PrimaryHostname string           `gorm:"type:varchar(255);uniqueIndex;not null" json:"primary_hostname"`
 	ExtraHostnames  []ExtraHostnames `gorm:"foreignKey:ApplicationID" json:"extra_hostnames,omitempty"`
+	Replicas        int              `gorm:"not null;default:1" json:"replicas"`
+	Stopped         bool             `gorm:"not null;default:false" json:"stopped"`
+	QuotaTier       string           `gorm:"type:varchar(16);not null;default:small" json:"quota_tier"`
+}
+
+func CanTransitionApplication(from string, to string) bool {
+	for _, allowed := range applicationStatusTransitions[from] {
+		if allowed == to {
+			return true
+		}
+	}
+	return false
+}
+
+func (a *Application) TransitionTo(status string, reason string) error {
+	if !CanTransitionApplication(a.Status, status) {
+		return fmt.Errorf("cannot move application from %s to %s", a.Status, status)
+	}
+
+	a.Status = status
+	a.StatusReason = reason
+	return nil
+}
+
+// HasResources reports whether infrastructure may exist for the application
+// and therefore has to be torn down before it is removed.
+func (a Application) HasResources() bool {
+	return a.Status == ApplicationStatusApproved || a.Status == ApplicationStatusSuspended || a.Status == ApplicationStatusFailed
+}
+
+// DesiredReplicas is the replica count the deployment should run with, taking
+// an owner-requested stop and an admin suspension into account.
+func (a Application) DesiredReplicas() int {
+	if a.Stopped || a.Status == ApplicationStatusSuspended {
+		return 0
+	}
+	return a.Replicas
 }
@@ -0,0 +1,22 @@
+package models
+
+import "gorm.io/gorm"
+
+const (
+	CollaboratorPermissionRead  = "read"
+	CollaboratorPermissionWrite = "write"
+)
+
+type ApplicationCollaborator struct {
+	gorm.Model
+	ApplicationID uint        `gorm:"not null;uniqueIndex:idx_application_collaborator" json:"application_id"`
+	Application   Application `gorm:"foreignKey:ApplicationID" json:"application,omitempty"`
+	UserID        uint        `gorm:"not null;uniqueIndex:idx_application_collaborator;index" json:"user_id"`
+	User          User        `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Permission    string      `gorm:"type:varchar(16);not null" json:"permission"`
+	InvitedByID   uint        `gorm:"not null" json:"invited_by_id"`
+}
+
+func IsValidCollaboratorPermission(permission string) bool {
+	return permission == CollaboratorPermissionRead || permission == CollaboratorPermissionWrite
+}
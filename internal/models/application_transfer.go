@@ -0,0 +1,27 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ApplicationTransferStatusPending   = "Pending"
+	ApplicationTransferStatusAccepted  = "Accepted"
+	ApplicationTransferStatusDeclined  = "Declined"
+	ApplicationTransferStatusCancelled = "Cancelled"
+)
+
+type ApplicationTransfer struct {
+	gorm.Model
+	ApplicationID uint        `gorm:"not null;index" json:"application_id"`
+	Application   Application `gorm:"foreignKey:ApplicationID" json:"application,omitempty"`
+	FromUserID    uint        `gorm:"not null" json:"from_user_id"`
+	FromUser      User        `gorm:"foreignKey:FromUserID" json:"from_user,omitempty"`
+	ToUserID      uint        `gorm:"not null;index" json:"to_user_id"`
+	ToUser        User        `gorm:"foreignKey:ToUserID" json:"to_user,omitempty"`
+	Status        string      `gorm:"type:varchar(16);not null;index" json:"status"`
+	ExpiresAt     time.Time   `gorm:"not null" json:"expires_at"`
+	RespondedAt   *time.Time  `json:"responded_at"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "gorm.io/gorm"
+
+const (
+	AuditActionRoleChanged         = "role.changed"
+	AuditActionApplicationTransfer = "application.transferred"
+	AuditActionApplicationReject   = "application.rejected"
+	AuditActionApplicationSuspend  = "application.suspended"
+	AuditActionApplicationResume   = "application.resumed"
+	AuditActionApplicationRestart  = "application.restarted"
+	AuditActionApplicationScale    = "application.scaled"
+	AuditActionApplicationStop     = "application.stopped"
+	AuditActionApplicationStart    = "application.started"
+	AuditActionMaxReplicasChanged  = "user.max_replicas_changed"
+	AuditActionApplicationQuota    = "application.quota_changed"
+)
+
+type AuditLog struct {
+	gorm.Model
+	ActorID    uint   `gorm:"not null;index" json:"actor_id"`
+	Actor      User   `gorm:"foreignKey:ActorID" json:"actor,omitempty"`
+	Action     string `gorm:"type:varchar(64);not null;index" json:"action"`
+	TargetType string `gorm:"type:varchar(64);not null" json:"target_type"`
+	TargetID   uint   `gorm:"not null;index" json:"target_id"`
+	Details    string `gorm:"type:text" json:"details"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	JobStatusQueued    = "Queued"
+	JobStatusRunning   = "Running"
+	JobStatusSucceeded = "Succeeded"
+	JobStatusFailed    = "Failed"
+)
+
+type Job struct {
+	gorm.Model
+	Type        string     `gorm:"type:varchar(64);not null;index" json:"type"`
+	Subject     string     `gorm:"type:varchar(128);index" json:"subject"`
+	Payload     string     `gorm:"type:text" json:"payload"`
+	Status      string     `gorm:"type:varchar(16);not null;index:idx_job_queue" json:"status"`
+	Attempts    int        `gorm:"not null;default:0" json:"attempts"`
+	MaxAttempts int        `gorm:"not null" json:"max_attempts"`
+	NextRunAt   time.Time  `gorm:"not null;index:idx_job_queue" json:"next_run_at"`
+	LastError   string     `gorm:"type:varchar(1024)" json:"last_error"`
+	CreatedByID *uint      `gorm:"index" json:"created_by_id"`
+	StartedAt   *time.Time `json:"started_at"`
+	FinishedAt  *time.Time `json:"finished_at"`
+}
+
+func (j Job) IsActive() bool {
+	return j.Status == JobStatusQueued || j.Status == JobStatusRunning
+}
@@ -0,0 +1,11 @@
+package models
+
+import "time"
+
+type LoginAttempt struct {
+	ID            uint       `gorm:"primarykey" json:"id"`
+	Key           string     `gorm:"type:varchar(255);uniqueIndex;not null" json:"key"`
+	Failures      int        `gorm:"not null;default:0" json:"failures"`
+	LastFailureAt time.Time  `gorm:"not null" json:"last_failure_at"`
+	LockedUntil   *time.Time `json:"locked_until"`
+}
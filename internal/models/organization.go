@@ -0,0 +1,47 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	OrganizationRoleOwner      = "owner"
+	OrganizationRoleMaintainer = "maintainer"
+	OrganizationRoleMember     = "member"
+)
+
+type Organization struct {
+	gorm.Model
+	Name         string               `gorm:"type:varchar(255);uniqueIndex;not null" json:"name"`
+	Description  string               `json:"description"`
+	Members      []OrganizationMember `gorm:"foreignKey:OrganizationID" json:"members,omitempty"`
+	Applications []Application        `gorm:"foreignKey:OrganizationID" json:"applications,omitempty"`
+}
+
+type OrganizationMember struct {
+	gorm.Model
+	OrganizationID uint         `gorm:"not null;uniqueIndex:idx_organization_member" json:"organization_id"`
+	Organization   Organization `gorm:"foreignKey:OrganizationID" json:"organization,omitempty"`
+	UserID         uint         `gorm:"not null;uniqueIndex:idx_organization_member;index" json:"user_id"`
+	User           User         `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Role           string       `gorm:"type:varchar(32);not null" json:"role"`
+}
+
+type OrganizationInvitation struct {
+	gorm.Model
+	OrganizationID uint         `gorm:"not null;index" json:"organization_id"`
+	Organization   Organization `gorm:"foreignKey:OrganizationID" json:"organization,omitempty"`
+	Email          string       `gorm:"type:varchar(255);not null;index" json:"email"`
+	Role           string       `gorm:"type:varchar(32);not null" json:"role"`
+	TokenHash      string       `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	InvitedByID    uint         `gorm:"not null" json:"invited_by_id"`
+	InvitedBy      User         `gorm:"foreignKey:InvitedByID" json:"invited_by,omitempty"`
+	ExpiresAt      time.Time    `gorm:"not null" json:"expires_at"`
+	AcceptedAt     *time.Time   `json:"accepted_at"`
+}
+
+func IsValidOrganizationRole(role string) bool {
+	return role == OrganizationRoleOwner || role == OrganizationRoleMaintainer || role == OrganizationRoleMember
+}
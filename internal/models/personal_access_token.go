@@ -0,0 +1,58 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ScopeUserRead           string = "user:read"
+	ScopeAppsRead           string = "apps:read"
+	ScopeAppsWrite          string = "apps:write"
+	ScopeEnvRead            string = "env:read"
+	ScopeEnvWrite           string = "env:write"
+	ScopeNotificationsRead  string = "notifications:read"
+	ScopeNotificationsWrite string = "notifications:write"
+)
+
+var PersonalAccessTokenScopes = []string{
+	ScopeUserRead,
+	ScopeAppsRead,
+	ScopeAppsWrite,
+	ScopeEnvRead,
+	ScopeEnvWrite,
+	ScopeNotificationsRead,
+	ScopeNotificationsWrite,
+}
+
+type PersonalAccessToken struct {
+	gorm.Model
+	UserID      uint       `gorm:"not null;index" json:"user_id"`
+	User        User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	Name        string     `gorm:"type:varchar(255);not null" json:"name"`
+	TokenHash   string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	TokenPrefix string     `gorm:"type:varchar(32);not null" json:"token_prefix"`
+	Scopes      string     `gorm:"type:varchar(255);not null" json:"scopes"`
+	ExpiresAt   *time.Time `json:"expires_at"`
+	LastUsedAt  *time.Time `json:"last_used_at"`
+}
+
+func (t PersonalAccessToken) ScopeList() []string {
+	if t.Scopes == "" {
+		return []string{}
+	}
+
+	return strings.Split(t.Scopes, ",")
+}
+
+func IsValidScope(scope string) bool {
+	for _, s := range PersonalAccessTokenScopes {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
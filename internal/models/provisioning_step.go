@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+const (
+	ProvisioningStepVault       = "vault"
+	ProvisioningStepDatabase    = "database"
+	ProvisioningStepQuota       = "quota"
+	ProvisioningStepGitOps      = "gitops"
+	ProvisioningStepCredentials = "credentials"
+)
+
+const (
+	ProvisioningStepStatusPending     = "Pending"
+	ProvisioningStepStatusCompleted   = "Completed"
+	ProvisioningStepStatusFailed      = "Failed"
+	ProvisioningStepStatusCompensated = "Compensated"
+)
+
+type ProvisioningStep struct {
+	gorm.Model
+	ApplicationID uint       `gorm:"not null;uniqueIndex:idx_provisioning_step" json:"application_id"`
+	Step          string     `gorm:"type:varchar(32);not null;uniqueIndex:idx_provisioning_step" json:"step"`
+	Status        string     `gorm:"type:varchar(16);not null" json:"status"`
+	Attempts      int        `gorm:"not null;default:0" json:"attempts"`
+	LastError     string     `gorm:"type:varchar(1024)" json:"last_error"`
+	CompletedAt   *time.Time `json:"completed_at"`
+}
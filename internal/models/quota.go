@@ -0,0 +1,68 @@
+package models
+
+const (
+	QuotaTierSmall  = "small"
+	QuotaTierMedium = "medium"
+	QuotaTierLarge  = "large"
+)
+
+// QuotaLimits are the namespace-wide totals of a tier plus the defaults given
+// to containers that do not declare their own resources. Values use
+// Kubernetes quantity notation.
+type QuotaLimits struct {
+	CPU                  string `json:"cpu"`
+	Memory               string `json:"memory"`
+	Pods                 int    `json:"pods"`
+	Storage              string `json:"storage"`
+	DefaultCPU           string `json:"default_cpu"`
+	DefaultMemory        string `json:"default_memory"`
+	DefaultCPURequest    string `json:"default_cpu_request"`
+	DefaultMemoryRequest string `json:"default_memory_request"`
+}
+
+// Each tier fits the default replica limit plus the surge pod of a rolling
+// update on the default container limits.
+var quotaTiers = map[string]QuotaLimits{
+	QuotaTierSmall: {
+		CPU:                  "1",
+		Memory:               "1Gi",
+		Pods:                 5,
+		Storage:              "5Gi",
+		DefaultCPU:           "250m",
+		DefaultMemory:        "256Mi",
+		DefaultCPURequest:    "100m",
+		DefaultMemoryRequest: "128Mi",
+	},
+	QuotaTierMedium: {
+		CPU:                  "2",
+		Memory:               "2Gi",
+		Pods:                 10,
+		Storage:              "10Gi",
+		DefaultCPU:           "500m",
+		DefaultMemory:        "512Mi",
+		DefaultCPURequest:    "200m",
+		DefaultMemoryRequest: "256Mi",
+	},
+	QuotaTierLarge: {
+		CPU:                  "4",
+		Memory:               "4Gi",
+		Pods:                 20,
+		Storage:              "20Gi",
+		DefaultCPU:           "1",
+		DefaultMemory:        "1Gi",
+		DefaultCPURequest:    "250m",
+		DefaultMemoryRequest: "512Mi",
+	},
+}
+
+func IsValidQuotaTier(tier string) bool {
+	_, ok := quotaTiers[tier]
+	return ok
+}
+
+func QuotaTierLimits(tier string) QuotaLimits {
+	if limits, ok := quotaTiers[tier]; ok {
+		return limits
+	}
+	return quotaTiers[QuotaTierSmall]
+}
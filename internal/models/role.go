@@ -0,0 +1,90 @@
+package models
+
+const (
+	RoleUser       = "user"
+	RoleViewer     = "viewer"
+	RoleReviewer   = "reviewer"
+	RoleOperator   = "operator"
+	RoleSuperadmin = "superadmin"
+)
+
+const (
+	PermissionUsersRead          = "users:read"
+	PermissionUsersManage        = "users:manage"
+	PermissionRolesManage        = "roles:manage"
+	PermissionApplicationsRead   = "applications:read"
+	PermissionApplicationsReview = "applications:review"
+	PermissionApplicationsManage = "applications:manage"
+	PermissionApplicationsDelete = "applications:delete"
+	PermissionAuditRead          = "audit:read"
+)
+
+var Roles = []string{
+	RoleUser,
+	RoleViewer,
+	RoleReviewer,
+	RoleOperator,
+	RoleSuperadmin,
+}
+
+var rolePermissions = map[string][]string{
+	RoleUser: {},
+	RoleViewer: {
+		PermissionUsersRead,
+		PermissionApplicationsRead,
+		PermissionAuditRead,
+	},
+	RoleReviewer: {
+		PermissionUsersRead,
+		PermissionApplicationsRead,
+		PermissionApplicationsReview,
+		PermissionAuditRead,
+	},
+	RoleOperator: {
+		PermissionUsersRead,
+		PermissionUsersManage,
+		PermissionApplicationsRead,
+		PermissionApplicationsReview,
+		PermissionApplicationsManage,
+		PermissionAuditRead,
+	},
+	RoleSuperadmin: {
+		PermissionUsersRead,
+		PermissionUsersManage,
+		PermissionRolesManage,
+		PermissionApplicationsRead,
+		PermissionApplicationsReview,
+		PermissionApplicationsManage,
+		PermissionApplicationsDelete,
+		PermissionAuditRead,
+	},
+}
+
+func IsValidRole(role string) bool {
+	_, ok := rolePermissions[role]
+	return ok
+}
+
+// IsStaffRole reports whether the role grants access to the admin API.
+func IsStaffRole(role string) bool {
+	return len(rolePermissions[role]) > 0
+}
+
+func RoleHasPermission(role string, permission string) bool {
+	for _, p := range rolePermissions[role] {
+		if p == permission {
+			return true
+		}
+	}
+	return false
+}
+
+func RolesWithPermission(permission string) []string {
+	var roles []string
+	for _, role := range Roles {
+		if RoleHasPermission(role, permission) {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
@@ -0,0 +1,23 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+type Session struct {
+	gorm.Model
+	UserID                   uint       `gorm:"not null;index" json:"user_id"`
+	User                     User       `gorm:"foreignKey:UserID" json:"user,omitempty"`
+	RefreshTokenHash         string     `gorm:"type:varchar(64);uniqueIndex;not null" json:"-"`
+	PreviousRefreshTokenHash string     `gorm:"type:varchar(64);index" json:"-"`
+	ExpiresAt                time.Time  `gorm:"not null" json:"expires_at"`
+	RevokedAt                *time.Time `json:"revoked_at"`
+	IPAddress                string     `gorm:"type:varchar(64)" json:"ip_address"`
+	UserAgent                string     `gorm:"type:varchar(255)" json:"user_agent"`
+}
+
+func (s Session) IsActive(now time.Time) bool {
+	return s.RevokedAt == nil && now.Before(s.ExpiresAt)
+}
@@ -1,13 +1,32 @@
 package models
 
-import "gorm.io/gorm"
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
 
 type User struct {
 	gorm.Model
-	Username      string         `gorm:"type:varchar(255);uniqueIndex;not null" json:"username"`
-	Email         string         `gorm:"type:varchar(255);uniqueIndex;not null" json:"email"`
-	Password      string         `gorm:"not null" json:"-"`
-	Subscriptions []Subscription `gorm:"foreignKey:UserID" json:"-"`
-	IsAdmin       bool           `gorm:"default:false" json:"is_admin"`
-	Applications  []Application  `gorm:"foreignKey:OwnerID" json:"applications,omitempty"`
+	Username        string         `gorm:"type:varchar(255);uniqueIndex;not null" json:"username"`
+	Email           string         `gorm:"type:varchar(255);uniqueIndex;not null" json:"email"`
+	EmailVerifiedAt *time.Time     `json:"email_verified_at"`
+	Password        string         `gorm:"not null" json:"-"`
+	TOTPSecret      string         `gorm:"type:varchar(64)" json:"-"`
+	TOTPEnabled     bool           `gorm:"default:false" json:"totp_enabled"`
+	TOTPLastStep    int64          `gorm:"default:0" json:"-"`
+	GithubID        *int64         `gorm:"uniqueIndex" json:"github_id,omitempty"`
+	GithubLogin     string         `gorm:"type:varchar(255)" json:"github_login,omitempty"`
+	Subscriptions   []Subscription `gorm:"foreignKey:UserID" json:"-"`
+	Role            string         `gorm:"type:varchar(32);default:user;not null;index" json:"role"`
+	MaxReplicas     int            `gorm:"not null;default:2" json:"max_replicas"`
+	Applications    []Application  `gorm:"foreignKey:OwnerID" json:"applications,omitempty"`
+}
+
+func (u User) HasPermission(permission string) bool {
+	return RoleHasPermission(u.Role, permission)
+}
+
+func (u User) IsEmailVerified() bool {
+	return u.EmailVerifiedAt != nil
 }
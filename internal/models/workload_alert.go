@@ -0,0 +1,18 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// WorkloadAlert is a container problem the owner has already been notified
+// about. It is removed once the problem clears.
+type WorkloadAlert struct {
+	gorm.Model
+	ApplicationID uint      `gorm:"not null;uniqueIndex:idx_workload_alert" json:"application_id"`
+	Container     string    `gorm:"type:varchar(253);not null;uniqueIndex:idx_workload_alert" json:"container"`
+	Reason        string    `gorm:"type:varchar(64);not null;uniqueIndex:idx_workload_alert" json:"reason"`
+	Pod           string    `gorm:"type:varchar(253)" json:"pod"`
+	LastSeenAt    time.Time `gorm:"not null" json:"last_seen_at"`
+}
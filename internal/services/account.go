@@ -0,0 +1,539 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/errors"
+	"github.com/injunweb/backend-server/pkg/token"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+// accountDeletionMaxAttempts gives the application delete jobs, which have
+// their own retries, time to finish before the account deletion gives up.
+const accountDeletionMaxAttempts = 10
+
+const jobTypeDeleteAccount = "account.delete"
+
+type AccountService struct {
+	db                  *gorm.DB
+	notificationService *NotificationService
+	provisioningService *ProvisioningService
+	jobService          *JobService
+}
+
+func NewAccountService(db *gorm.DB, notificationService *NotificationService, provisioningService *ProvisioningService, jobService *JobService) *AccountService {
+	s := &AccountService{db: db, notificationService: notificationService, provisioningService: provisioningService, jobService: jobService}
+
+	jobService.Register(jobTypeDeleteAccount, JobHandler{Run: s.runDeleteAccountJob, OnFailure: s.failDeleteAccountJob, MaxAttempts: accountDeletionMaxAttempts})
+
+	return s
+}
+
+type ExportAccountResponse struct {
+	ExportedAt string `json:"exported_at"`
+	User       struct {
+		ID              uint   `json:"id"`
+		Username        string `json:"username"`
+		Email           string `json:"email"`
+		EmailVerifiedAt string `json:"email_verified_at"`
+		Role            string `json:"role"`
+		TOTPEnabled     bool   `json:"totp_enabled"`
+		GithubLogin     string `json:"github_login"`
+		CreatedAt       string `json:"created_at"`
+		UpdatedAt       string `json:"updated_at"`
+	} `json:"user"`
+	Applications []struct {
+		ID              uint     `json:"id"`
+		Name            string   `json:"name"`
+		GitURL          string   `json:"git_url"`
+		Branch          string   `json:"branch"`
+		Port            string   `json:"port"`
+		Description     string   `json:"description"`
+		Status          string   `json:"status"`
+		PrimaryHostname string   `json:"primary_hostname"`
+		ExtraHostnames  []string `json:"extra_hostnames"`
+		CreatedAt       string   `json:"created_at"`
+	} `json:"applications"`
+	Notifications []struct {
+		Message   string `json:"message"`
+		IsRead    bool   `json:"is_read"`
+		CreatedAt string `json:"created_at"`
+	} `json:"notifications"`
+	Subscriptions []struct {
+		Endpoint  string `json:"endpoint"`
+		CreatedAt string `json:"created_at"`
+	} `json:"subscriptions"`
+	Sessions []struct {
+		IPAddress string `json:"ip_address"`
+		UserAgent string `json:"user_agent"`
+		CreatedAt string `json:"created_at"`
+		ExpiresAt string `json:"expires_at"`
+		RevokedAt string `json:"revoked_at"`
+	} `json:"sessions"`
+	PersonalAccessTokens []struct {
+		Name        string   `json:"name"`
+		TokenPrefix string   `json:"token_prefix"`
+		Scopes      []string `json:"scopes"`
+		CreatedAt   string   `json:"created_at"`
+		LastUsedAt  string   `json:"last_used_at"`
+	} `json:"personal_access_tokens"`
+	Organizations []struct {
+		Name string `json:"name"`
+		Role string `json:"role"`
+	} `json:"organizations"`
+}
+
+func (s *AccountService) ExportAccount(userId uint) (ExportAccountResponse, errors.CustomError) {
+	var user models.User
+	if err := s.db.First(&user, userId).Error; err != nil {
+		return ExportAccountResponse{}, errors.NotFound("user not found")
+	}
+
+	var applications []models.Application
+	if err := s.db.Preload("ExtraHostnames").Where("owner_id = ?", userId).Find(&applications).Error; err != nil {
+		return ExportAccountResponse{}, errors.Internal("failed to retrieve applications")
+	}
+
+	var notifications []models.Notification
+	if err := s.db.Where("user_id = ?", userId).Order("created_at DESC").Find(&notifications).Error; err != nil {
+		return ExportAccountResponse{}, errors.Internal("failed to retrieve notifications")
+	}
+
+	var subscriptions []models.Subscription
+	if err := s.db.Where("user_id = ?", userId).Find(&subscriptions).Error; err != nil {
+		return ExportAccountResponse{}, errors.Internal("failed to retrieve subscriptions")
+	}
+
+	var sessions []models.Session
+	if err := s.db.Where("user_id = ?", userId).Order("created_at DESC").Find(&sessions).Error; err != nil {
+		return ExportAccountResponse{}, errors.Internal("failed to retrieve sessions")
+	}
+
+	var tokens []models.PersonalAccessToken
+	if err := s.db.Where("user_id = ?", userId).Find(&tokens).Error; err != nil {
+		return ExportAccountResponse{}, errors.Internal("failed to retrieve tokens")
+	}
+
+	var memberships []models.OrganizationMember
+	if err := s.db.Preload("Organization").Where("user_id = ?", userId).Find(&memberships).Error; err != nil {
+		return ExportAccountResponse{}, errors.Internal("failed to retrieve organizations")
+	}
+
+	var response ExportAccountResponse
+	response.ExportedAt = time.Now().Format("2006-01-02 15:04:05")
+	response.User.ID = user.ID
+	response.User.Username = user.Username
+	response.User.Email = user.Email
+	response.User.EmailVerifiedAt = formatOptionalTime(user.EmailVerifiedAt)
+	response.User.Role = user.Role
+	response.User.TOTPEnabled = user.TOTPEnabled
+	response.User.GithubLogin = user.GithubLogin
+	response.User.CreatedAt = user.CreatedAt.Format("2006-01-02 15:04:05")
+	response.User.UpdatedAt = user.UpdatedAt.Format("2006-01-02 15:04:05")
+
+	for _, app := range applications {
+		var extraHostnames []string
+		for _, hostname := range app.ExtraHostnames {
+			extraHostnames = append(extraHostnames, hostname.Hostname)
+		}
+
+		response.Applications = append(response.Applications, struct {
+			ID              uint     `json:"id"`
+			Name            string   `json:"name"`
+			GitURL          string   `json:"git_url"`
+			Branch          string   `json:"branch"`
+			Port            string   `json:"port"`
+			Description     string   `json:"description"`
+			Status          string   `json:"status"`
+			PrimaryHostname string   `json:"primary_hostname"`
+			ExtraHostnames  []string `json:"extra_hostnames"`
+			CreatedAt       string   `json:"created_at"`
+		}{
+			ID:              app.ID,
+			Name:            app.Name,
+			GitURL:          app.GitURL,
+			Branch:          app.Branch,
+			Port:            app.Port,
+			Description:     app.Description,
+			Status:          app.Status,
+			PrimaryHostname: app.PrimaryHostname,
+			ExtraHostnames:  extraHostnames,
+			CreatedAt:       app.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	for _, notification := range notifications {
+		response.Notifications = append(response.Notifications, struct {
+			Message   string `json:"message"`
+			IsRead    bool   `json:"is_read"`
+			CreatedAt string `json:"created_at"`
+		}{
+			Message:   notification.Message,
+			IsRead:    notification.IsRead,
+			CreatedAt: notification.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	for _, subscription := range subscriptions {
+		response.Subscriptions = append(response.Subscriptions, struct {
+			Endpoint  string `json:"endpoint"`
+			CreatedAt string `json:"created_at"`
+		}{
+			Endpoint:  subscription.Endpoint,
+			CreatedAt: subscription.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	for _, session := range sessions {
+		response.Sessions = append(response.Sessions, struct {
+			IPAddress string `json:"ip_address"`
+			UserAgent string `json:"user_agent"`
+			CreatedAt string `json:"created_at"`
+			ExpiresAt string `json:"expires_at"`
+			RevokedAt string `json:"revoked_at"`
+		}{
+			IPAddress: session.IPAddress,
+			UserAgent: session.UserAgent,
+			CreatedAt: session.CreatedAt.Format("2006-01-02 15:04:05"),
+			ExpiresAt: session.ExpiresAt.Format("2006-01-02 15:04:05"),
+			RevokedAt: formatOptionalTime(session.RevokedAt),
+		})
+	}
+
+	for _, t := range tokens {
+		response.PersonalAccessTokens = append(response.PersonalAccessTokens, struct {
+			Name        string   `json:"name"`
+			TokenPrefix string   `json:"token_prefix"`
+			Scopes      []string `json:"scopes"`
+			CreatedAt   string   `json:"created_at"`
+			LastUsedAt  string   `json:"last_used_at"`
+		}{
+			Name:        t.Name,
+			TokenPrefix: t.TokenPrefix,
+			Scopes:      t.ScopeList(),
+			CreatedAt:   t.CreatedAt.Format("2006-01-02 15:04:05"),
+			LastUsedAt:  formatOptionalTime(t.LastUsedAt),
+		})
+	}
+
+	for _, membership := range memberships {
+		response.Organizations = append(response.Organizations, struct {
+			Name string `json:"name"`
+			Role string `json:"role"`
+		}{
+			Name: membership.Organization.Name,
+			Role: membership.Role,
+		})
+	}
+
+	return response, nil
+}
+
+type DeleteAccountRequest struct {
+	Password           string `json:"password" binding:"required"`
+	DeleteApplications bool   `json:"delete_applications"`
+}
+
+type DeleteAccountResponse struct {
+	JobID   uint   `json:"job_id"`
+	Message string `json:"message"`
+}
+
+type accountJobPayload struct {
+	UserID uint `json:"user_id"`
+}
+
+// DeleteAccount signs the user out everywhere and queues deletion of their
+// applications. The users row is anonymized and soft deleted by a follow-up
+// job once those are gone, rather than removed, so audit log entries keep a
+// valid actor reference.
+func (s *AccountService) DeleteAccount(userId uint, req DeleteAccountRequest) (DeleteAccountResponse, errors.CustomError) {
+	var job models.Job
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userId).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+			return errors.Unauthorized("password is incorrect")
+		}
+
+		if user.Role == models.RoleSuperadmin {
+			var count int64
+			if err := tx.Model(&models.User{}).Where("role = ? AND id <> ?", models.RoleSuperadmin, userId).Count(&count).Error; err != nil {
+				return errors.Internal("failed to check superadmins")
+			}
+			if count == 0 {
+				return errors.BadRequest("cannot delete the last superadmin")
+			}
+		}
+
+		var ownedOrganizations []models.OrganizationMember
+		if err := tx.Where("user_id = ? AND role = ?", userId, models.OrganizationRoleOwner).Find(&ownedOrganizations).Error; err != nil {
+			return errors.Internal("failed to check organizations")
+		}
+		for _, membership := range ownedOrganizations {
+			if err := ensureAnotherOwner(tx, membership.OrganizationID, userId); err != nil {
+				return errors.BadRequest("transfer or delete organizations you solely own before deleting your account")
+			}
+		}
+
+		// Organization applications stay with the organization.
+		var organizationApplications []models.Application
+		if err := tx.Where("owner_id = ? AND organization_id IS NOT NULL", userId).Find(&organizationApplications).Error; err != nil {
+			return errors.Internal("failed to retrieve applications")
+		}
+		for _, application := range organizationApplications {
+			if err := reassignToOrganizationOwner(tx, application, userId); err != nil {
+				return err
+			}
+		}
+
+		var applications []models.Application
+		if err := tx.Where("owner_id = ? AND organization_id IS NULL", userId).Find(&applications).Error; err != nil {
+			return errors.Internal("failed to retrieve applications")
+		}
+
+		var approved []string
+		for _, application := range applications {
+			if application.Status == models.ApplicationStatusProvisioning || application.Status == models.ApplicationStatusDeprovisioning {
+				return errors.Conflict(fmt.Sprintf("wait for %s of %s to finish before deleting your account", strings.ToLower(application.Status), application.Name))
+			}
+			if application.HasResources() {
+				approved = append(approved, application.Name)
+			}
+		}
+		if len(approved) > 0 && !req.DeleteApplications {
+			return errors.Conflict(fmt.Sprintf("delete or transfer approved applications first, or set delete_applications: %s", strings.Join(approved, ", ")))
+		}
+
+		for _, application := range applications {
+			// A deletion the user started earlier is already queued.
+			if application.Status == models.ApplicationStatusDeleting {
+				continue
+			}
+
+			hadResources := application.HasResources()
+			if err := transitionApplication(tx, &application, models.ApplicationStatusDeleting, ""); err != nil {
+				return err
+			}
+
+			if _, err := s.provisioningService.EnqueueDelete(tx, application, hadResources, userId); err != nil {
+				return err
+			}
+		}
+
+		if err := lockUser(tx, user); err != nil {
+			return err
+		}
+
+		var err errors.CustomError
+		job, err = s.jobService.Enqueue(tx, jobTypeDeleteAccount, fmt.Sprintf("user:%d", userId), accountJobPayload{UserID: userId}, &userId)
+		return err
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return DeleteAccountResponse{}, customErr
+		}
+		return DeleteAccountResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	return DeleteAccountResponse{
+		JobID:   job.ID,
+		Message: "Account deletion started",
+	}, nil
+}
+
+// runDeleteAccountJob waits for the user's application delete jobs and then
+// erases the remaining personal data.
+func (s *AccountService) runDeleteAccountJob(payload []byte) error {
+	var data accountJobPayload
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return err
+	}
+
+	var remaining int64
+	if err := s.db.Model(&models.Application{}).Where("owner_id = ?", data.UserID).Count(&remaining).Error; err != nil {
+		return fmt.Errorf("failed to count applications: %v", err)
+	}
+	if remaining > 0 {
+		return fmt.Errorf("%d applications are still being deleted", remaining)
+	}
+
+	var username string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, data.UserID).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		var applications []models.Application
+		if err := tx.Unscoped().Where("owner_id = ?", data.UserID).Find(&applications).Error; err != nil {
+			return errors.Internal("failed to retrieve applications")
+		}
+		for _, application := range applications {
+			if err := purgeApplicationRecords(tx, application.ID); err != nil {
+				return err
+			}
+		}
+
+		if err := purgeUserRecords(tx, data.UserID); err != nil {
+			return err
+		}
+
+		username = user.Username
+		return anonymizeUser(tx, user)
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notificationService.CreateAdminNotification(fmt.Sprintf("User deleted their account: %s", username))
+	return nil
+}
+
+func (s *AccountService) failDeleteAccountJob(payload []byte, jobErr error) {
+	var data accountJobPayload
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return
+	}
+
+	s.notificationService.CreateAdminNotification(fmt.Sprintf("Deletion of account %d could not be completed: %v", data.UserID, jobErr))
+}
+
+func purgeApplicationRecords(tx *gorm.DB, appId uint) errors.CustomError {
+	records := []interface{}{
+		&models.ExtraHostnames{},
+		&models.ApplicationCollaborator{},
+		&models.ApplicationTransfer{},
+		&models.ProvisioningStep{},
+		&models.WorkloadAlert{},
+	}
+	for _, record := range records {
+		if err := tx.Unscoped().Where("application_id = ?", appId).Delete(record).Error; err != nil {
+			return errors.Internal("failed to delete application data")
+		}
+	}
+
+	if err := tx.Unscoped().Delete(&models.Application{}, appId).Error; err != nil {
+		return errors.Internal("failed to delete application")
+	}
+
+	return nil
+}
+
+func purgeUserRecords(tx *gorm.DB, userId uint) errors.CustomError {
+	records := []interface{}{
+		&models.Session{},
+		&models.PasswordResetToken{},
+		&models.EmailVerificationToken{},
+		&models.RecoveryCode{},
+		&models.PersonalAccessToken{},
+		&models.Notification{},
+		&models.Subscription{},
+		&models.OrganizationMember{},
+		&models.ApplicationCollaborator{},
+	}
+	for _, record := range records {
+		if err := tx.Unscoped().Where("user_id = ?", userId).Delete(record).Error; err != nil {
+			return errors.Internal("failed to delete account data")
+		}
+	}
+
+	if err := tx.Unscoped().Where("from_user_id = ? OR to_user_id = ?", userId, userId).Delete(&models.ApplicationTransfer{}).Error; err != nil {
+		return errors.Internal("failed to delete transfers")
+	}
+
+	if err := tx.Unscoped().Where("invited_by_id = ?", userId).Delete(&models.OrganizationInvitation{}).Error; err != nil {
+		return errors.Internal("failed to delete invitations")
+	}
+
+	return nil
+}
+
+// reassignToOrganizationOwner hands an organization application owned by a
+// departing user to another owner of the organization.
+func reassignToOrganizationOwner(tx *gorm.DB, application models.Application, userId uint) errors.CustomError {
+	var owner models.OrganizationMember
+	err := tx.Where("organization_id = ? AND role = ? AND user_id <> ?", *application.OrganizationID, models.OrganizationRoleOwner, userId).
+		Order("id").First(&owner).Error
+	if err != nil {
+		return errors.Conflict(fmt.Sprintf("organization application %s has no other owner to take it over", application.Name))
+	}
+
+	if err := tx.Model(&application).Update("owner_id", owner.UserID).Error; err != nil {
+		return errors.Internal("failed to update application owner")
+	}
+
+	return cancelPendingTransfers(tx, application.ID)
+}
+
+// lockUser replaces the credentials of an account pending deletion and ends
+// its sessions so it cannot be used while its applications are removed.
+func lockUser(tx *gorm.DB, user models.User) errors.CustomError {
+	randomPassword, err := token.Generate(32)
+	if err != nil {
+		return errors.Internal("failed to generate password")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.Internal("failed to hash password")
+	}
+
+	err = tx.Model(&user).Updates(map[string]interface{}{
+		"password":     string(hashedPassword),
+		"github_id":    nil,
+		"github_login": "",
+	}).Error
+	if err != nil {
+		return errors.Internal("failed to lock user")
+	}
+
+	if err := revokeUserSessions(tx, user.ID, 0); err != nil {
+		return err
+	}
+
+	return revokePersonalAccessTokens(tx, user.ID)
+}
+
+func anonymizeUser(tx *gorm.DB, user models.User) errors.CustomError {
+	randomPassword, err := token.Generate(32)
+	if err != nil {
+		return errors.Internal("failed to generate password")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return errors.Internal("failed to hash password")
+	}
+
+	err = tx.Model(&user).Updates(map[string]interface{}{
+		"username":          fmt.Sprintf("deleted-%d", user.ID),
+		"email":             fmt.Sprintf("deleted-%d@invalid", user.ID),
+		"email_verified_at": nil,
+		"password":          string(hashedPassword),
+		"totp_secret":       "",
+		"totp_enabled":      false,
+		"github_id":         nil,
+		"github_login":      "",
+		"role":              models.RoleUser,
+	}).Error
+	if err != nil {
+		return errors.Internal("failed to anonymize user")
+	}
+
+	if err := tx.Delete(&user).Error; err != nil {
+		return errors.Internal("failed to delete user")
+	}
+
+	return nil
+}
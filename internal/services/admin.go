@@ -4,24 +4,22 @@ import (
 	"fmt"
 
 	"github.com/injunweb/backend-server/internal/models"
-	"github.com/injunweb/backend-server/pkg/database"
-	"github.com/injunweb/backend-server/pkg/email"
 	"github.com/injunweb/backend-server/pkg/errors"
 	"github.com/injunweb/backend-server/pkg/github"
-	"github.com/injunweb/backend-server/pkg/harbor"
-	"github.com/injunweb/backend-server/pkg/kubernetes"
-	"github.com/injunweb/backend-server/pkg/vault"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 )
 
 type AdminService struct {
 	db                  *gorm.DB
 	notificationService *NotificationService
+	loginLimiter        *LoginLimiter
+	provisioningService *ProvisioningService
 }
 
-func NewAdminService(db *gorm.DB, notificationService *NotificationService) *AdminService {
-	return &AdminService{db: db, notificationService: notificationService}
+func NewAdminService(db *gorm.DB, notificationService *NotificationService, loginLimiter *LoginLimiter, provisioningService *ProvisioningService) *AdminService {
+	return &AdminService{db: db, notificationService: notificationService, loginLimiter: loginLimiter, provisioningService: provisioningService}
 }
 
 type GetUsersByAdminResponse struct {
@@ -29,10 +27,15 @@ type GetUsersByAdminResponse struct {
 		ID       uint   `json:"id"`
 		Username string `json:"username"`
 		Email    string `json:"email"`
+		Role     string `json:"role"`
 	} `json:"users"`
 }
 
-func (s *AdminService) GetUsersByAdmin() (GetUsersByAdminResponse, errors.CustomError) {
+func (s *AdminService) GetUsersByAdmin(actorId uint) (GetUsersByAdminResponse, errors.CustomError) {
+	if err := authorize(s.db, actorId, models.PermissionUsersRead); err != nil {
+		return GetUsersByAdminResponse{}, err
+	}
+
 	var users []models.User
 	if err := s.db.Find(&users).Error; err != nil {
 		return GetUsersByAdminResponse{}, errors.NotFound("users not found")
@@ -44,10 +47,12 @@ func (s *AdminService) GetUsersByAdmin() (GetUsersByAdminResponse, errors.Custom
 			ID       uint   `json:"id"`
 			Username string `json:"username"`
 			Email    string `json:"email"`
+			Role     string `json:"role"`
 		}{
 			ID:       user.ID,
 			Username: user.Username,
 			Email:    user.Email,
+			Role:     user.Role,
 		})
 	}
 
@@ -55,25 +60,166 @@ func (s *AdminService) GetUsersByAdmin() (GetUsersByAdminResponse, errors.Custom
 }
 
 type GetUserByAdminResponse struct {
-	ID        uint   `json:"id"`
-	Username  string `json:"username"`
-	Email     string `json:"email"`
-	IsAdmin   bool   `json:"is_admin"`
-	CreatedAt string `json:"created_at"`
+	ID          uint   `json:"id"`
+	Username    string `json:"username"`
+	Email       string `json:"email"`
+	Role        string `json:"role"`
+	MaxReplicas int    `json:"max_replicas"`
+	CreatedAt   string `json:"created_at"`
 }
 
-func (s *AdminService) GetUserByAdmin(userId uint) (GetUserByAdminResponse, errors.CustomError) {
+func (s *AdminService) GetUserByAdmin(actorId uint, userId uint) (GetUserByAdminResponse, errors.CustomError) {
+	if err := authorize(s.db, actorId, models.PermissionUsersRead); err != nil {
+		return GetUserByAdminResponse{}, err
+	}
+
 	var user models.User
 	if err := s.db.First(&user, userId).Error; err != nil {
 		return GetUserByAdminResponse{}, errors.NotFound("user not found")
 	}
 
 	return GetUserByAdminResponse{
-		ID:        user.ID,
-		Username:  user.Username,
-		Email:     user.Email,
-		IsAdmin:   user.IsAdmin,
-		CreatedAt: user.CreatedAt.Format("2006-01-02 15:04:05"),
+		ID:          user.ID,
+		Username:    user.Username,
+		Email:       user.Email,
+		Role:        user.Role,
+		MaxReplicas: user.MaxReplicas,
+		CreatedAt:   user.CreatedAt.Format("2006-01-02 15:04:05"),
+	}, nil
+}
+
+type UnlockUserByAdminResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *AdminService) UnlockUserByAdmin(actorId uint, userId uint) (UnlockUserByAdminResponse, errors.CustomError) {
+	if err := authorize(s.db, actorId, models.PermissionUsersManage); err != nil {
+		return UnlockUserByAdminResponse{}, err
+	}
+
+	var user models.User
+	if err := s.db.First(&user, userId).Error; err != nil {
+		return UnlockUserByAdminResponse{}, errors.NotFound("user not found")
+	}
+
+	if err := s.loginLimiter.Unlock(user.Username); err != nil {
+		return UnlockUserByAdminResponse{}, err
+	}
+
+	s.notificationService.CreateNotification(user.ID, "Your account has been unlocked by an administrator")
+
+	return UnlockUserByAdminResponse{
+		Message: "User unlocked successfully",
+	}, nil
+}
+
+type UpdateUserRoleRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type UpdateUserRoleByAdminResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *AdminService) UpdateUserRoleByAdmin(actorId uint, userId uint, req UpdateUserRoleRequest) (UpdateUserRoleByAdminResponse, errors.CustomError) {
+	if err := authorize(s.db, actorId, models.PermissionRolesManage); err != nil {
+		return UpdateUserRoleByAdminResponse{}, err
+	}
+
+	if !models.IsValidRole(req.Role) {
+		return UpdateUserRoleByAdminResponse{}, errors.BadRequest("invalid role")
+	}
+
+	var user models.User
+	var previousRole string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Lock every superadmin row so concurrent demotions cannot both pass the
+		// last-superadmin check.
+		var superadmins []models.User
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("role = ?", models.RoleSuperadmin).Find(&superadmins).Error; err != nil {
+			return errors.Internal("failed to retrieve superadmins")
+		}
+
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).First(&user, userId).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		if user.Role == req.Role {
+			return errors.Conflict("user already has the requested role")
+		}
+
+		if user.Role == models.RoleSuperadmin && len(superadmins) <= 1 {
+			return errors.BadRequest("cannot remove the last superadmin")
+		}
+
+		previousRole = user.Role
+		if err := tx.Model(&user).Update("role", req.Role).Error; err != nil {
+			return errors.Internal("failed to update user role")
+		}
+
+		// The role is embedded in access token claims, so existing sessions must
+		// go and the user signs in again with the new role.
+		if err := revokeUserSessions(tx, user.ID, 0); err != nil {
+			return err
+		}
+
+		return recordAuditLog(tx, actorId, models.AuditActionRoleChanged, auditTargetUser, user.ID, fmt.Sprintf("role of %s changed from %s to %s", user.Username, previousRole, req.Role))
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return UpdateUserRoleByAdminResponse{}, customErr
+		}
+		return UpdateUserRoleByAdminResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	s.notificationService.CreateNotification(user.ID, fmt.Sprintf("Your role has been changed from %s to %s, please sign in again", previousRole, req.Role))
+
+	return UpdateUserRoleByAdminResponse{
+		Message: "User role updated successfully",
+	}, nil
+}
+
+type UpdateMaxReplicasRequest struct {
+	MaxReplicas int `json:"max_replicas" binding:"required,min=1"`
+}
+
+type UpdateMaxReplicasByAdminResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *AdminService) UpdateMaxReplicasByAdmin(actorId uint, userId uint, req UpdateMaxReplicasRequest) (UpdateMaxReplicasByAdminResponse, errors.CustomError) {
+	if err := authorize(s.db, actorId, models.PermissionUsersManage); err != nil {
+		return UpdateMaxReplicasByAdminResponse{}, err
+	}
+
+	var user models.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&user, userId).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		previous := user.MaxReplicas
+		if err := tx.Model(&user).Update("max_replicas", req.MaxReplicas).Error; err != nil {
+			return errors.Internal("failed to update replica limit")
+		}
+
+		// Applications already above the new limit keep running as they are;
+		// the limit applies to the next scale request.
+		return recordAuditLog(tx, actorId, models.AuditActionMaxReplicasChanged, auditTargetUser, user.ID, fmt.Sprintf("replica limit of %s changed from %d to %d", user.Username, previous, req.MaxReplicas))
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return UpdateMaxReplicasByAdminResponse{}, customErr
+		}
+		return UpdateMaxReplicasByAdminResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	s.notificationService.CreateNotification(user.ID, fmt.Sprintf("Your applications can now run up to %d replicas", req.MaxReplicas))
+
+	return UpdateMaxReplicasByAdminResponse{
+		Message: "Replica limit updated successfully",
 	}, nil
 }
 
@@ -86,7 +232,11 @@ type GetApplicationsByAdminResponse struct {
 	} `json:"applications"`
 }
 
-func (s *AdminService) GetApplicationsByAdmin(userId uint) (GetApplicationsByAdminResponse, errors.CustomError) {
+func (s *AdminService) GetApplicationsByAdmin(actorId uint, userId uint) (GetApplicationsByAdminResponse, errors.CustomError) {
+	if err := authorize(s.db, actorId, models.PermissionApplicationsRead); err != nil {
+		return GetApplicationsByAdminResponse{}, err
+	}
+
 	var applications []models.Application
 	if err := s.db.Where("owner_id = ?", userId).Find(&applications).Error; err != nil {
 		return GetApplicationsByAdminResponse{}, errors.NotFound("applications not found")
@@ -121,7 +271,11 @@ type GetAllApplicationsByAdminResponse struct {
 	} `json:"applications"`
 }
 
-func (s *AdminService) GetAllApplicationsByAdmin() (GetAllApplicationsByAdminResponse, errors.CustomError) {
+func (s *AdminService) GetAllApplicationsByAdmin(actorId uint) (GetAllApplicationsByAdminResponse, errors.CustomError) {
+	if err := authorize(s.db, actorId, models.PermissionApplicationsRead); err != nil {
+		return GetAllApplicationsByAdminResponse{}, err
+	}
+
 	var applications []models.Application
 	if err := s.db.Preload("Owner").Order("created_at DESC").Find(&applications).Error; err != nil {
 		return GetAllApplicationsByAdminResponse{}, errors.Internal("failed to retrieve applications")
@@ -150,50 +304,41 @@ func (s *AdminService) GetAllApplicationsByAdmin() (GetAllApplicationsByAdminRes
 }
 
 type ApproveApplicationByAdminResponse struct {
+	JobID   uint   `json:"job_id"`
 	Message string `json:"message"`
 }
 
-func (s *AdminService) ApproveApplicationByAdmin(appId uint) (ApproveApplicationByAdminResponse, errors.CustomError) {
-	var application models.Application
-	var owner models.User
+func (s *AdminService) ApproveApplicationByAdmin(actorId uint, appId uint) (ApproveApplicationByAdminResponse, errors.CustomError) {
+	if err := authorize(s.db, actorId, models.PermissionApplicationsReview); err != nil {
+		return ApproveApplicationByAdminResponse{}, err
+	}
 
+	var job models.Job
 	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var application models.Application
 		if err := tx.First(&application, appId).Error; err != nil {
 			return errors.NotFound("application not found")
 		}
 
-		if application.Status != models.ApplicationStatusPending {
-			return errors.BadRequest("application already approved")
-		}
-
+		var owner models.User
 		if err := tx.First(&owner, application.OwnerID).Error; err != nil {
 			return errors.NotFound("failed to find user email")
 		}
 
-		if err := vault.InitSecret(application.Name, map[string]interface{}{"INIT": "INIT"}); err != nil {
-			return errors.Internal(fmt.Sprintf("failed to initialize Vault secret: %v", err))
+		if !owner.IsEmailVerified() {
+			return errors.BadRequest("owner email address not verified")
 		}
 
-		if err := github.TriggerWriteValuesWorkflow(application); err != nil {
-			return errors.Internal(fmt.Sprintf("failed to trigger GitHub workflow: %v", err))
+		if err := transitionApplication(tx, &application, models.ApplicationStatusProvisioning, ""); err != nil {
+			return err
 		}
 
-		password, err := database.CreateDatabaseAndUser(application.Name)
+		var err errors.CustomError
+		job, err = s.provisioningService.EnqueueProvision(tx, application, actorId)
 		if err != nil {
-			return errors.Internal(fmt.Sprintf("failed to create database and user: %v", err))
+			return err
 		}
 
-		if err := email.SendApprovalEmail(owner.Email, application.Name, password); err != nil {
-			return errors.Internal(fmt.Sprintf("failed to send email: %v", err))
-		}
-
-		application.Status = models.ApplicationStatusApproved
-		if err := tx.Save(&application).Error; err != nil {
-			return errors.Internal("failed to update application status")
-		}
-
-		s.notificationService.CreateNotification(application.OwnerID, fmt.Sprintf("Your application %s has been approved", application.Name))
-
 		return nil
 	})
 
@@ -205,55 +350,40 @@ func (s *AdminService) ApproveApplicationByAdmin(appId uint) (ApproveApplication
 	}
 
 	return ApproveApplicationByAdminResponse{
-		Message: "Application approved successfully",
+		JobID:   job.ID,
+		Message: "Application approval started",
 	}, nil
 }
 
 type CancelApproveApplicationByAdminResponse struct {
+	JobID   uint   `json:"job_id"`
 	Message string `json:"message"`
 }
 
-func (s *AdminService) CancelApproveApplicationByAdmin(appId uint) (CancelApproveApplicationByAdminResponse, errors.CustomError) {
-	var application models.Application
+func (s *AdminService) CancelApproveApplicationByAdmin(actorId uint, appId uint) (CancelApproveApplicationByAdminResponse, errors.CustomError) {
+	if err := authorize(s.db, actorId, models.PermissionApplicationsManage); err != nil {
+		return CancelApproveApplicationByAdminResponse{}, err
+	}
 
+	var job models.Job
 	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var application models.Application
 		if err := tx.First(&application, appId).Error; err != nil {
 			return errors.NotFound("application not found")
 		}
 
-		if application.Status != models.ApplicationStatusApproved {
+		if !application.HasResources() {
 			return errors.BadRequest("application not approved")
 		}
 
-		if kubernetes.NamespaceExists(application.Name) {
-			if err := kubernetes.DeleteNamespace(application.Name); err != nil {
-				return errors.Internal(fmt.Sprintf("failed to delete namespace: %v", err))
-			}
-		}
-
-		if exists, err := harbor.RepositoryExists(application.Name); err != nil {
-			return errors.Internal(fmt.Sprintf("failed to check Harbor repository: %v", err))
-		} else if exists {
-			if err := harbor.DeleteRepository(application.Name); err != nil {
-				return errors.Internal(fmt.Sprintf("failed to delete Harbor repository: %v", err))
-			}
-		}
-
-		if err := vault.DeleteSecret(application.Name); err != nil {
-			return errors.Internal(fmt.Sprintf("failed to delete secret: %v", err))
+		if err := transitionApplication(tx, &application, models.ApplicationStatusDeprovisioning, ""); err != nil {
+			return err
 		}
 
-		if err := database.DeleteDatabaseAndUser(application.Name); err != nil {
-			return errors.Internal(fmt.Sprintf("failed to delete database and user: %v", err))
-		}
-
-		if err := github.TriggerRemovePipelineWorkflow(application); err != nil {
-			return errors.Internal(fmt.Sprintf("failed to trigger GitHub workflow: %v", err))
-		}
-
-		application.Status = models.ApplicationStatusPending
-		if err := tx.Save(&application).Error; err != nil {
-			return errors.Internal("failed to update application status")
+		var err errors.CustomError
+		job, err = s.provisioningService.EnqueueTeardown(tx, application, actorId)
+		if err != nil {
+			return err
 		}
 
 		return nil
@@ -267,7 +397,8 @@ func (s *AdminService) CancelApproveApplicationByAdmin(appId uint) (CancelApprov
 	}
 
 	return CancelApproveApplicationByAdminResponse{
-		Message: "Application approval canceled successfully",
+		JobID:   job.ID,
+		Message: "Application approval cancellation started",
 	}, nil
 }
 
@@ -279,7 +410,11 @@ type UpdateCustomHostnameByAdminResponse struct {
 	Message string `json:"message"`
 }
 
-func (s *AdminService) UpdatePrimaryHostnameByAdmin(appId uint, request UpdateCustomHostnameRequest) (UpdateCustomHostnameByAdminResponse, errors.CustomError) {
+func (s *AdminService) UpdatePrimaryHostnameByAdmin(actorId uint, appId uint, request UpdateCustomHostnameRequest) (UpdateCustomHostnameByAdminResponse, errors.CustomError) {
+	if err := authorize(s.db, actorId, models.PermissionApplicationsManage); err != nil {
+		return UpdateCustomHostnameByAdminResponse{}, err
+	}
+
 	var application models.Application
 
 	err := s.db.Transaction(func(tx *gorm.DB) error {
@@ -316,26 +451,38 @@ func (s *AdminService) UpdatePrimaryHostnameByAdmin(appId uint, request UpdateCu
 }
 
 type GetApplicationByAdminResponse struct {
-	ID              uint     `json:"id"`
-	Name            string   `json:"name"`
-	GitURL          string   `json:"git_url"`
-	Branch          string   `json:"branch"`
-	Port            string   `json:"port"`
-	Description     string   `json:"description"`
-	OwnerID         uint     `json:"owner_id"`
-	Status          string   `json:"status"`
-	OwnerUsername   string   `json:"owner_username"`
-	PrimaryHostname string   `json:"primary_hostname"`
-	ExtraHostnames  []string `json:"extra_hostnames"`
-	CreationDate    string   `json:"creation_date"`
+	ID                uint                       `json:"id"`
+	Name              string                     `json:"name"`
+	GitURL            string                     `json:"git_url"`
+	Branch            string                     `json:"branch"`
+	Port              string                     `json:"port"`
+	Description       string                     `json:"description"`
+	OwnerID           uint                       `json:"owner_id"`
+	Status            string                     `json:"status"`
+	StatusReason      string                     `json:"status_reason"`
+	QuotaTier         string                     `json:"quota_tier"`
+	OwnerUsername     string                     `json:"owner_username"`
+	PrimaryHostname   string                     `json:"primary_hostname"`
+	ExtraHostnames    []string                   `json:"extra_hostnames"`
+	ProvisioningSteps []ProvisioningStepResponse `json:"provisioning_steps"`
+	CreationDate      string                     `json:"creation_date"`
 }
 
-func (s *AdminService) GetApplicationByAdmin(appId uint) (GetApplicationByAdminResponse, errors.CustomError) {
+func (s *AdminService) GetApplicationByAdmin(actorId uint, appId uint) (GetApplicationByAdminResponse, errors.CustomError) {
+	if err := authorize(s.db, actorId, models.PermissionApplicationsRead); err != nil {
+		return GetApplicationByAdminResponse{}, err
+	}
+
 	var application models.Application
 	if err := s.db.Preload("Owner").Preload("ExtraHostnames").First(&application, appId).Error; err != nil {
 		return GetApplicationByAdminResponse{}, errors.NotFound("application not found")
 	}
 
+	steps, err := s.provisioningService.GetSteps(application.ID)
+	if err != nil {
+		return GetApplicationByAdminResponse{}, err
+	}
+
 	return GetApplicationByAdminResponse{
 		ID:              application.ID,
 		Name:            application.Name,
@@ -345,6 +492,8 @@ func (s *AdminService) GetApplicationByAdmin(appId uint) (GetApplicationByAdminR
 		Description:     application.Description,
 		OwnerID:         application.OwnerID,
 		Status:          application.Status,
+		StatusReason:    application.StatusReason,
+		QuotaTier:       application.QuotaTier,
 		OwnerUsername:   application.Owner.Username,
 		PrimaryHostname: application.PrimaryHostname,
 		ExtraHostnames: func() []string {
@@ -354,6 +503,7 @@ func (s *AdminService) GetApplicationByAdmin(appId uint) (GetApplicationByAdminR
 			}
 			return extraHostnames
 		}(),
-		CreationDate: application.CreatedAt.Format("2006-01-02 15:04:05"),
+		ProvisioningSteps: steps,
+		CreationDate:      application.CreatedAt.Format("2006-01-02 15:04:05"),
 	}, nil
 }
@@ -2,13 +2,11 @@ package services
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/injunweb/backend-server/internal/models"
-	"github.com/injunweb/backend-server/pkg/database"
 	"github.com/injunweb/backend-server/pkg/errors"
 	"github.com/injunweb/backend-server/pkg/github"
-	"github.com/injunweb/backend-server/pkg/harbor"
-	"github.com/injunweb/backend-server/pkg/kubernetes"
 	"github.com/injunweb/backend-server/pkg/validator"
 	"github.com/injunweb/backend-server/pkg/vault"
 
@@ -18,10 +16,17 @@ import (
 type ApplicationService struct {
 	db                  *gorm.DB
 	notificationService *NotificationService
+	provisioningService *ProvisioningService
+	logStreams          *logStreamLimiter
 }
 
-func NewApplicationService(db *gorm.DB, notificationService *NotificationService) *ApplicationService {
-	return &ApplicationService{db: db, notificationService: notificationService}
+func NewApplicationService(db *gorm.DB, notificationService *NotificationService, provisioningService *ProvisioningService) *ApplicationService {
+	return &ApplicationService{
+		db:                  db,
+		notificationService: notificationService,
+		provisioningService: provisioningService,
+		logStreams:          &logStreamLimiter{active: make(map[uint]int)},
+	}
 }
 
 type GetApplicationsResponse struct {
@@ -37,7 +42,7 @@ type GetApplicationsResponse struct {
 
 func (s *ApplicationService) GetApplications(userId uint) (GetApplicationsResponse, errors.CustomError) {
 	var applications []models.Application
-	if err := s.db.Where("owner_id = ?", userId).Find(&applications).Error; err != nil {
+	if err := accessibleApplications(s.db, userId).Find(&applications).Error; err != nil {
 		return GetApplicationsResponse{}, errors.Internal("failed to retrieve applications")
 	}
 
@@ -64,11 +69,12 @@ func (s *ApplicationService) GetApplications(userId uint) (GetApplicationsRespon
 }
 
 type SubmitApplicationRequest struct {
-	Name        string `json:"name" binding:"required"`
-	GitURL      string `json:"git_url" binding:"required"`
-	Branch      string `json:"branch" binding:"required"`
-	Port        string `json:"port" binding:"required"`
-	Description string `json:"description" binding:"required"`
+	Name           string `json:"name" binding:"required"`
+	GitURL         string `json:"git_url" binding:"required"`
+	Branch         string `json:"branch" binding:"required"`
+	Port           string `json:"port" binding:"required"`
+	Description    string `json:"description" binding:"required"`
+	OrganizationID *uint  `json:"organization_id"`
 }
 
 type SubmitApplicationResponse struct {
@@ -84,7 +90,30 @@ func (s *ApplicationService) SubmitApplication(userId uint, req SubmitApplicatio
 		return SubmitApplicationResponse{}, errors.BadRequest("invalid port")
 	}
 
+	if !validator.IsValidGitURL(req.GitURL) {
+		return SubmitApplicationResponse{}, errors.BadRequest("invalid git url")
+	}
+
+	if !validator.IsValidBranch(req.Branch) {
+		return SubmitApplicationResponse{}, errors.BadRequest("invalid branch")
+	}
+
 	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userId).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		if !user.IsEmailVerified() {
+			return errors.Forbidden("email address must be verified before submitting an application")
+		}
+
+		if req.OrganizationID != nil {
+			if _, err := authorizeOrganization(tx, userId, *req.OrganizationID, models.OrganizationRoleMaintainer); err != nil {
+				return err
+			}
+		}
+
 		var existingApp models.Application
 		if err := tx.Where("name = ?", req.Name).First(&existingApp).Error; err == nil {
 			return errors.Conflict("application name already exists")
@@ -100,6 +129,7 @@ func (s *ApplicationService) SubmitApplication(userId uint, req SubmitApplicatio
 			PrimaryHostname: fmt.Sprintf("%s.%s", req.Name, "ijw.app"),
 			ExtraHostnames:  []models.ExtraHostnames{},
 			OwnerID:         userId,
+			OrganizationID:  req.OrganizationID,
 		}
 
 		if err := tx.Create(&application).Error; err != nil {
@@ -131,7 +161,11 @@ type GetApplicationResponse struct {
 	Description     string   `json:"description"`
 	CreatedAt       string   `json:"created_at"`
 	OwnerID         uint     `json:"owner_id"`
+	OrganizationID  *uint    `json:"organization_id"`
 	Status          string   `json:"status"`
+	StatusReason    string   `json:"status_reason"`
+	Replicas        int      `json:"replicas"`
+	Stopped         bool     `json:"stopped"`
 	PrimaryHostname string   `json:"primary_hostname"`
 	ExtraHostnames  []string `json:"extra_hostnames"`
 }
@@ -142,8 +176,8 @@ func (s *ApplicationService) GetApplication(userId uint, appId uint) (GetApplica
 		return GetApplicationResponse{}, errors.NotFound("application not found")
 	}
 
-	if application.OwnerID != userId {
-		return GetApplicationResponse{}, errors.Forbidden("permission denied")
+	if err := authorizeApplication(s.db, userId, application, applicationAccessRead); err != nil {
+		return GetApplicationResponse{}, err
 	}
 
 	return GetApplicationResponse{
@@ -155,7 +189,11 @@ func (s *ApplicationService) GetApplication(userId uint, appId uint) (GetApplica
 		Description:     application.Description,
 		CreatedAt:       application.CreatedAt.Format("2006-01-02 15:04:05"),
 		OwnerID:         application.OwnerID,
+		OrganizationID:  application.OrganizationID,
 		Status:          application.Status,
+		StatusReason:    application.StatusReason,
+		Replicas:        application.Replicas,
+		Stopped:         application.Stopped,
 		PrimaryHostname: application.PrimaryHostname,
 		ExtraHostnames: func() []string {
 			var extraHostnames []string
@@ -167,54 +205,147 @@ func (s *ApplicationService) GetApplication(userId uint, appId uint) (GetApplica
 	}, nil
 }
 
-type DeleteApplicationResponse struct {
+type UpdateApplicationRequest struct {
+	GitURL      *string `json:"git_url"`
+	Branch      *string `json:"branch"`
+	Port        *string `json:"port"`
+	Description *string `json:"description"`
+}
+
+type UpdateApplicationResponse struct {
 	Message string `json:"message"`
 }
 
-func (s *ApplicationService) DeleteApplication(userId uint, appId uint) (DeleteApplicationResponse, errors.CustomError) {
+func (s *ApplicationService) UpdateApplication(userId uint, appId uint, req UpdateApplicationRequest) (UpdateApplicationResponse, errors.CustomError) {
+	if req.GitURL != nil && !validator.IsValidGitURL(*req.GitURL) {
+		return UpdateApplicationResponse{}, errors.BadRequest("invalid git url")
+	}
+
+	if req.Branch != nil && !validator.IsValidBranch(*req.Branch) {
+		return UpdateApplicationResponse{}, errors.BadRequest("invalid branch")
+	}
+
+	if req.Port != nil && !validator.IsValidPort(*req.Port) {
+		return UpdateApplicationResponse{}, errors.BadRequest("invalid port")
+	}
+
+	var application models.Application
+	var gitURLChanged bool
+	var resubmitted bool
 	err := s.db.Transaction(func(tx *gorm.DB) error {
-		var application models.Application
 		if err := tx.First(&application, appId).Error; err != nil {
 			return errors.NotFound("application not found")
 		}
 
-		if application.OwnerID != userId {
-			return errors.Forbidden("permission denied")
+		if err := authorizeApplication(tx, userId, application, applicationAccessWrite); err != nil {
+			return err
 		}
 
-		if application.Status == models.ApplicationStatusApproved {
-			if kubernetes.NamespaceExists(application.Name) {
-				if err := kubernetes.DeleteNamespace(application.Name); err != nil {
-					return errors.Internal(fmt.Sprintf("failed to delete namespace: %v", err))
-				}
-			}
+		// A running job would deploy the application as it was when the job
+		// started, so the edit would never reach the GitOps values.
+		switch application.Status {
+		case models.ApplicationStatusProvisioning, models.ApplicationStatusDeprovisioning, models.ApplicationStatusDeleting:
+			return errors.Conflict(fmt.Sprintf("wait for %s of %s to finish before editing it", strings.ToLower(application.Status), application.Name))
+		}
 
-			if exists, err := harbor.RepositoryExists(application.Name); err != nil {
-				return errors.Internal(fmt.Sprintf("failed to check Harbor repository: %v", err))
-			} else if exists {
-				if err := harbor.DeleteRepository(application.Name); err != nil {
-					return errors.Internal(fmt.Sprintf("failed to delete Harbor repository: %v", err))
-				}
-			}
+		// Only the edited columns are written so concurrent status, replica
+		// and quota changes are not overwritten.
+		updates := make(map[string]interface{})
+		deploymentChanged := false
+		if req.GitURL != nil && *req.GitURL != application.GitURL {
+			application.GitURL = *req.GitURL
+			updates["git_url"] = application.GitURL
+			deploymentChanged = true
+			gitURLChanged = true
+		}
+		if req.Branch != nil && *req.Branch != application.Branch {
+			application.Branch = *req.Branch
+			updates["branch"] = application.Branch
+			deploymentChanged = true
+		}
+		if req.Port != nil && *req.Port != application.Port {
+			application.Port = *req.Port
+			updates["port"] = application.Port
+			deploymentChanged = true
+		}
+		if req.Description != nil {
+			application.Description = *req.Description
+			updates["description"] = application.Description
+		}
 
-			if err := vault.DeleteSecret(application.Name); err != nil {
-				return errors.Internal(fmt.Sprintf("failed to delete secret: %v", err))
+		if len(updates) > 0 {
+			if err := tx.Model(&application).Updates(updates).Error; err != nil {
+				return errors.Internal("failed to update application")
 			}
+		}
 
-			if err := database.DeleteDatabaseAndUser(application.Name); err != nil {
-				return errors.Internal(fmt.Sprintf("failed to delete database and user: %v", err))
+		// Editing a rejected application resubmits it for review.
+		if application.Status == models.ApplicationStatusRejected {
+			resubmitted = true
+			if err := transitionApplication(tx, &application, models.ApplicationStatusPending, ""); err != nil {
+				return err
 			}
+		}
 
-			if err := github.TriggerRemovePipelineWorkflow(application); err != nil {
+		if deploymentChanged && application.Status == models.ApplicationStatusApproved {
+			if err := github.TriggerWriteValuesWorkflow(application); err != nil {
 				return errors.Internal(fmt.Sprintf("failed to trigger GitHub workflow: %v", err))
 			}
 		}
 
-		if err := tx.Delete(&application).Error; err != nil {
-			return errors.Internal("failed to delete application")
+		return nil
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return UpdateApplicationResponse{}, customErr
+		}
+		return UpdateApplicationResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	// Approval was given for a specific repository, so reviewers should know
+	// when a live application starts building from somewhere else.
+	if gitURLChanged && application.Status == models.ApplicationStatusApproved {
+		s.notificationService.CreateAdminNotification(fmt.Sprintf("Approved application %s now deploys from %s", application.Name, application.GitURL))
+	}
+
+	if resubmitted {
+		s.notificationService.CreateAdminNotification(fmt.Sprintf("Application resubmitted: %s", application.Name))
+	}
+
+	return UpdateApplicationResponse{
+		Message: "Application updated successfully",
+	}, nil
+}
+
+type DeleteApplicationResponse struct {
+	JobID   uint   `json:"job_id"`
+	Message string `json:"message"`
+}
+
+func (s *ApplicationService) DeleteApplication(userId uint, appId uint) (DeleteApplicationResponse, errors.CustomError) {
+	var job models.Job
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var application models.Application
+		if err := tx.First(&application, appId).Error; err != nil {
+			return errors.NotFound("application not found")
+		}
+
+		if err := authorizeApplication(tx, userId, application, applicationAccessAdmin); err != nil {
+			return err
+		}
+
+		hadResources := application.HasResources()
+		if err := transitionApplication(tx, &application, models.ApplicationStatusDeleting, ""); err != nil {
+			return err
+		}
+
+		var err errors.CustomError
+		job, err = s.provisioningService.EnqueueDelete(tx, application, hadResources, userId)
+		if err != nil {
+			return err
 		}
 
-		s.notificationService.CreateAdminNotification(fmt.Sprintf("Application deleted: %s", application.Name))
 		return nil
 	})
 
@@ -226,7 +357,8 @@ func (s *ApplicationService) DeleteApplication(userId uint, appId uint) (DeleteA
 	}
 
 	return DeleteApplicationResponse{
-		Message: "Application deleted successfully",
+		JobID:   job.ID,
+		Message: "Application deletion started",
 	}, nil
 }
 
@@ -249,8 +381,8 @@ func (s *ApplicationService) AddExtralHostname(userId uint, appId uint, req AddE
 			return errors.NotFound("application not found")
 		}
 
-		if application.OwnerID != userId {
-			return errors.Forbidden("permission denied")
+		if err := authorizeApplication(tx, userId, application, applicationAccessWrite); err != nil {
+			return err
 		}
 
 		if application.Status != models.ApplicationStatusApproved {
@@ -321,8 +453,8 @@ func (s *ApplicationService) DeleteExtraHostname(userId uint, appId uint, req De
 			return errors.NotFound("application not found")
 		}
 
-		if application.OwnerID != userId {
-			return errors.Forbidden("permission denied")
+		if err := authorizeApplication(tx, userId, application, applicationAccessWrite); err != nil {
+			return err
 		}
 
 		if application.Status != models.ApplicationStatusApproved {
@@ -374,8 +506,8 @@ func (s *ApplicationService) GetEnvironments(userId uint, appId uint) (GetEnviro
 		return GetEnvironmentsResponse{}, errors.NotFound("application not found")
 	}
 
-	if application.OwnerID != userId {
-		return GetEnvironmentsResponse{}, errors.Forbidden("permission denied")
+	if err := authorizeApplication(s.db, userId, application, applicationAccessWrite); err != nil {
+		return GetEnvironmentsResponse{}, err
 	}
 
 	if application.Status != models.ApplicationStatusApproved {
@@ -419,8 +551,8 @@ func (s *ApplicationService) UpdateEnvironment(userId uint, appId uint, req Upda
 			return errors.NotFound("application not found")
 		}
 
-		if application.OwnerID != userId {
-			return errors.Forbidden("permission denied")
+		if err := authorizeApplication(tx, userId, application, applicationAccessWrite); err != nil {
+			return err
 		}
 
 		if application.Status != models.ApplicationStatusApproved {
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+type GetCollaboratorsResponse struct {
+	Collaborators []struct {
+		UserID     uint   `json:"user_id"`
+		Username   string `json:"username"`
+		Permission string `json:"permission"`
+		CreatedAt  string `json:"created_at"`
+	} `json:"collaborators"`
+}
+
+func (s *ApplicationService) GetCollaborators(userId uint, appId uint) (GetCollaboratorsResponse, errors.CustomError) {
+	var application models.Application
+	if err := s.db.First(&application, appId).Error; err != nil {
+		return GetCollaboratorsResponse{}, errors.NotFound("application not found")
+	}
+
+	if err := authorizeApplication(s.db, userId, application, applicationAccessRead); err != nil {
+		return GetCollaboratorsResponse{}, err
+	}
+
+	var collaborators []models.ApplicationCollaborator
+	if err := s.db.Preload("User").Where("application_id = ?", appId).Find(&collaborators).Error; err != nil {
+		return GetCollaboratorsResponse{}, errors.Internal("failed to retrieve collaborators")
+	}
+
+	var response GetCollaboratorsResponse
+	for _, collaborator := range collaborators {
+		response.Collaborators = append(response.Collaborators, struct {
+			UserID     uint   `json:"user_id"`
+			Username   string `json:"username"`
+			Permission string `json:"permission"`
+			CreatedAt  string `json:"created_at"`
+		}{
+			UserID:     collaborator.UserID,
+			Username:   collaborator.User.Username,
+			Permission: collaborator.Permission,
+			CreatedAt:  collaborator.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return response, nil
+}
+
+type AddCollaboratorRequest struct {
+	Username   string `json:"username" binding:"required"`
+	Permission string `json:"permission" binding:"required"`
+}
+
+type AddCollaboratorResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *ApplicationService) AddCollaborator(userId uint, appId uint, req AddCollaboratorRequest) (AddCollaboratorResponse, errors.CustomError) {
+	if !models.IsValidCollaboratorPermission(req.Permission) {
+		return AddCollaboratorResponse{}, errors.BadRequest("invalid permission")
+	}
+
+	var application models.Application
+	var invitee models.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&application, appId).Error; err != nil {
+			return errors.NotFound("application not found")
+		}
+
+		if err := authorizeApplication(tx, userId, application, applicationAccessAdmin); err != nil {
+			return err
+		}
+
+		if err := tx.Where("username = ?", req.Username).First(&invitee).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		if invitee.ID == application.OwnerID {
+			return errors.BadRequest("user already owns this application")
+		}
+
+		var count int64
+		if err := tx.Model(&models.ApplicationCollaborator{}).Where("application_id = ? AND user_id = ?", appId, invitee.ID).Count(&count).Error; err != nil {
+			return errors.Internal("failed to check collaborators")
+		}
+		if count > 0 {
+			return errors.Conflict("user is already a collaborator")
+		}
+
+		collaborator := models.ApplicationCollaborator{
+			ApplicationID: appId,
+			UserID:        invitee.ID,
+			Permission:    req.Permission,
+			InvitedByID:   userId,
+		}
+		if err := tx.Create(&collaborator).Error; err != nil {
+			return errors.Internal("failed to add collaborator")
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return AddCollaboratorResponse{}, customErr
+		}
+		return AddCollaboratorResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	s.notificationService.CreateNotification(invitee.ID, fmt.Sprintf("You were given %s access to application %s", req.Permission, application.Name))
+
+	return AddCollaboratorResponse{
+		Message: "Collaborator added successfully",
+	}, nil
+}
+
+type UpdateCollaboratorRequest struct {
+	Permission string `json:"permission" binding:"required"`
+}
+
+type UpdateCollaboratorResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *ApplicationService) UpdateCollaborator(userId uint, appId uint, collaboratorUserId uint, req UpdateCollaboratorRequest) (UpdateCollaboratorResponse, errors.CustomError) {
+	if !models.IsValidCollaboratorPermission(req.Permission) {
+		return UpdateCollaboratorResponse{}, errors.BadRequest("invalid permission")
+	}
+
+	var application models.Application
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&application, appId).Error; err != nil {
+			return errors.NotFound("application not found")
+		}
+
+		if err := authorizeApplication(tx, userId, application, applicationAccessAdmin); err != nil {
+			return err
+		}
+
+		var collaborator models.ApplicationCollaborator
+		if err := tx.Where("application_id = ? AND user_id = ?", appId, collaboratorUserId).First(&collaborator).Error; err != nil {
+			return errors.NotFound("collaborator not found")
+		}
+
+		collaborator.Permission = req.Permission
+		if err := tx.Save(&collaborator).Error; err != nil {
+			return errors.Internal("failed to update collaborator")
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return UpdateCollaboratorResponse{}, customErr
+		}
+		return UpdateCollaboratorResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	s.notificationService.CreateNotification(collaboratorUserId, fmt.Sprintf("Your access to application %s is now %s", application.Name, req.Permission))
+
+	return UpdateCollaboratorResponse{
+		Message: "Collaborator updated successfully",
+	}, nil
+}
+
+type RemoveCollaboratorResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *ApplicationService) RemoveCollaborator(userId uint, appId uint, collaboratorUserId uint) (RemoveCollaboratorResponse, errors.CustomError) {
+	var application models.Application
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&application, appId).Error; err != nil {
+			return errors.NotFound("application not found")
+		}
+
+		// Collaborators may remove themselves without admin access.
+		if userId != collaboratorUserId {
+			if err := authorizeApplication(tx, userId, application, applicationAccessAdmin); err != nil {
+				return err
+			}
+		}
+
+		result := tx.Unscoped().Where("application_id = ? AND user_id = ?", appId, collaboratorUserId).Delete(&models.ApplicationCollaborator{})
+		if result.Error != nil {
+			return errors.Internal("failed to remove collaborator")
+		}
+		if result.RowsAffected == 0 {
+			return errors.NotFound("collaborator not found")
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return RemoveCollaboratorResponse{}, customErr
+		}
+		return RemoveCollaboratorResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	if userId != collaboratorUserId {
+		s.notificationService.CreateNotification(collaboratorUserId, fmt.Sprintf("Your access to application %s was removed", application.Name))
+	}
+
+	return RemoveCollaboratorResponse{
+		Message: "Collaborator removed successfully",
+	}, nil
+}
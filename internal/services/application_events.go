@@ -0,0 +1,98 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/errors"
+	"github.com/injunweb/backend-server/pkg/kubernetes"
+)
+
+type GetApplicationEventsResponse struct {
+	Events []struct {
+		Type      string `json:"type"`
+		Reason    string `json:"reason"`
+		Message   string `json:"message"`
+		Object    string `json:"object"`
+		Count     int32  `json:"count"`
+		FirstSeen string `json:"first_seen"`
+		LastSeen  string `json:"last_seen"`
+	} `json:"events"`
+	Terminations []struct {
+		Pod          string `json:"pod"`
+		Container    string `json:"container"`
+		Reason       string `json:"reason"`
+		Message      string `json:"message"`
+		ExitCode     int32  `json:"exit_code"`
+		RestartCount int32  `json:"restart_count"`
+		FinishedAt   string `json:"finished_at"`
+	} `json:"terminations"`
+}
+
+func (s *ApplicationService) GetApplicationEvents(userId uint, appId uint) (GetApplicationEventsResponse, errors.CustomError) {
+	var application models.Application
+	if err := s.db.First(&application, appId).Error; err != nil {
+		return GetApplicationEventsResponse{}, errors.NotFound("application not found")
+	}
+
+	if err := authorizeApplication(s.db, userId, application, applicationAccessRead); err != nil {
+		return GetApplicationEventsResponse{}, err
+	}
+
+	var response GetApplicationEventsResponse
+	if application.Status != models.ApplicationStatusApproved && application.Status != models.ApplicationStatusSuspended {
+		return response, nil
+	}
+
+	events, err := kubernetes.ListEvents(application.Name)
+	if err != nil {
+		return GetApplicationEventsResponse{}, errors.BadGateway(fmt.Sprintf("failed to list events: %v", err))
+	}
+
+	terminations, err := kubernetes.ListContainerTerminations(application.Name)
+	if err != nil {
+		return GetApplicationEventsResponse{}, errors.BadGateway(fmt.Sprintf("failed to list terminations: %v", err))
+	}
+
+	for _, event := range events {
+		response.Events = append(response.Events, struct {
+			Type      string `json:"type"`
+			Reason    string `json:"reason"`
+			Message   string `json:"message"`
+			Object    string `json:"object"`
+			Count     int32  `json:"count"`
+			FirstSeen string `json:"first_seen"`
+			LastSeen  string `json:"last_seen"`
+		}{
+			Type:      event.Type,
+			Reason:    event.Reason,
+			Message:   event.Message,
+			Object:    fmt.Sprintf("%s/%s", event.Kind, event.Name),
+			Count:     event.Count,
+			FirstSeen: event.FirstSeen.Format("2006-01-02 15:04:05"),
+			LastSeen:  event.LastSeen.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	for _, termination := range terminations {
+		response.Terminations = append(response.Terminations, struct {
+			Pod          string `json:"pod"`
+			Container    string `json:"container"`
+			Reason       string `json:"reason"`
+			Message      string `json:"message"`
+			ExitCode     int32  `json:"exit_code"`
+			RestartCount int32  `json:"restart_count"`
+			FinishedAt   string `json:"finished_at"`
+		}{
+			Pod:          termination.Pod,
+			Container:    termination.Container,
+			Reason:       termination.Reason,
+			Message:      termination.Message,
+			ExitCode:     termination.ExitCode,
+			RestartCount: termination.RestartCount,
+			FinishedAt:   termination.FinishedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return response, nil
+}
@@ -0,0 +1,57 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/errors"
+	"github.com/injunweb/backend-server/pkg/kubernetes"
+)
+
+const applicationHealthSuspended = "suspended"
+
+type GetApplicationStatusResponse struct {
+	Status      string                        `json:"status"`
+	Health      string                        `json:"health"`
+	Deployments []kubernetes.DeploymentStatus `json:"deployments"`
+	ReplicaSets []kubernetes.ReplicaSetStatus `json:"replica_sets"`
+	Pods        []kubernetes.PodStatus        `json:"pods"`
+	CheckedAt   string                        `json:"checked_at"`
+}
+
+func (s *ApplicationService) GetApplicationStatus(userId uint, appId uint) (GetApplicationStatusResponse, errors.CustomError) {
+	var application models.Application
+	if err := s.db.First(&application, appId).Error; err != nil {
+		return GetApplicationStatusResponse{}, errors.NotFound("application not found")
+	}
+
+	if err := authorizeApplication(s.db, userId, application, applicationAccessRead); err != nil {
+		return GetApplicationStatusResponse{}, err
+	}
+
+	response := GetApplicationStatusResponse{
+		Status:    application.Status,
+		Health:    kubernetes.HealthNotDeployed,
+		CheckedAt: time.Now().Format("2006-01-02 15:04:05"),
+	}
+
+	if application.Status != models.ApplicationStatusApproved && application.Status != models.ApplicationStatusSuspended {
+		return response, nil
+	}
+
+	status, err := kubernetes.GetNamespaceStatus(application.Name)
+	if err != nil {
+		return GetApplicationStatusResponse{}, errors.BadGateway(fmt.Sprintf("failed to read deployment status: %v", err))
+	}
+
+	response.Deployments = status.Deployments
+	response.ReplicaSets = status.ReplicaSets
+	response.Pods = status.Pods
+	response.Health = status.Health()
+	if application.Status == models.ApplicationStatusSuspended {
+		response.Health = applicationHealthSuspended
+	}
+
+	return response, nil
+}
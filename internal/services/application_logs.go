@@ -0,0 +1,203 @@
+package services
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/errors"
+	"github.com/injunweb/backend-server/pkg/kubernetes"
+)
+
+const (
+	maxLogStreamsPerUser = 3
+	maxLogStreamPods     = 10
+	defaultLogTailLines  = 100
+	maxLogTailLines      = 5000
+)
+
+type GetApplicationLogsRequest struct {
+	Pod       string `form:"pod"`
+	Container string `form:"container"`
+	TailLines int64  `form:"tail_lines"`
+	SinceTime string `form:"since_time"`
+	Previous  bool   `form:"previous"`
+	Follow    *bool  `form:"follow"`
+}
+
+type LogLine struct {
+	Pod   string `json:"pod"`
+	Line  string `json:"line,omitempty"`
+	Error string `json:"error,omitempty"`
+}
+
+// LogStream delivers lines from one or more pods until every pod stream has
+// ended. Close must be called once the caller stops reading.
+type LogStream struct {
+	Lines <-chan LogLine
+	close func()
+}
+
+func (s *LogStream) Close() {
+	s.close()
+}
+
+type logStreamLimiter struct {
+	mu     sync.Mutex
+	active map[uint]int
+}
+
+func (l *logStreamLimiter) acquire(userId uint) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if l.active[userId] >= maxLogStreamsPerUser {
+		return false
+	}
+	l.active[userId]++
+	return true
+}
+
+func (l *logStreamLimiter) release(userId uint) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.active[userId]--
+	if l.active[userId] <= 0 {
+		delete(l.active, userId)
+	}
+}
+
+func (s *ApplicationService) OpenLogStream(ctx context.Context, userId uint, appId uint, req GetApplicationLogsRequest) (*LogStream, errors.CustomError) {
+	var application models.Application
+	if err := s.db.First(&application, appId).Error; err != nil {
+		return nil, errors.NotFound("application not found")
+	}
+
+	// Logs can contain the same secrets as the environment, so they need the
+	// same access.
+	if err := authorizeApplication(s.db, userId, application, applicationAccessWrite); err != nil {
+		return nil, err
+	}
+
+	if application.Status != models.ApplicationStatusApproved {
+		return nil, errors.BadRequest("application is not running")
+	}
+
+	options := kubernetes.LogOptions{
+		Container: req.Container,
+		Follow:    req.Follow == nil || *req.Follow,
+		Previous:  req.Previous,
+	}
+	// Logs of a previous container are complete and cannot be followed.
+	if options.Previous {
+		options.Follow = false
+	}
+
+	tailLines := req.TailLines
+	if tailLines <= 0 {
+		tailLines = defaultLogTailLines
+	}
+	if tailLines > maxLogTailLines {
+		tailLines = maxLogTailLines
+	}
+	options.TailLines = &tailLines
+
+	if req.SinceTime != "" {
+		sinceTime, err := time.Parse(time.RFC3339, req.SinceTime)
+		if err != nil {
+			return nil, errors.BadRequest("since_time must be an RFC 3339 timestamp")
+		}
+		options.SinceTime = &sinceTime
+	}
+
+	pods, err := kubernetes.ListPodNames(application.Name)
+	if err != nil {
+		return nil, errors.BadGateway(fmt.Sprintf("failed to list pods: %v", err))
+	}
+
+	if req.Pod != "" {
+		found := false
+		for _, pod := range pods {
+			if pod == req.Pod {
+				found = true
+			}
+		}
+		if !found {
+			return nil, errors.NotFound("pod not found")
+		}
+		pods = []string{req.Pod}
+	}
+
+	if len(pods) == 0 {
+		return nil, errors.NotFound("no pods running")
+	}
+	if len(pods) > maxLogStreamPods {
+		pods = pods[:maxLogStreamPods]
+	}
+
+	if !s.logStreams.acquire(userId) {
+		return nil, errors.TooManyRequests(fmt.Sprintf("at most %d log streams can be open at once", maxLogStreamsPerUser))
+	}
+
+	streamCtx, cancel := context.WithCancel(ctx)
+	lines := make(chan LogLine, 64)
+
+	var wg sync.WaitGroup
+	for _, pod := range pods {
+		wg.Add(1)
+		go func(pod string) {
+			defer wg.Done()
+			streamPodLogs(streamCtx, application.Name, pod, options, lines)
+		}(pod)
+	}
+
+	go func() {
+		wg.Wait()
+		close(lines)
+	}()
+
+	var once sync.Once
+	return &LogStream{
+		Lines: lines,
+		close: func() {
+			once.Do(func() {
+				cancel()
+				s.logStreams.release(userId)
+			})
+		},
+	}, nil
+}
+
+func streamPodLogs(ctx context.Context, namespace string, pod string, options kubernetes.LogOptions, lines chan<- LogLine) {
+	send := func(line LogLine) bool {
+		select {
+		case lines <- line:
+			return true
+		case <-ctx.Done():
+			return false
+		}
+	}
+
+	stream, err := kubernetes.StreamPodLogs(ctx, namespace, pod, options)
+	if err != nil {
+		send(LogLine{Pod: pod, Error: err.Error()})
+		return
+	}
+	defer stream.Close()
+
+	scanner := bufio.NewScanner(stream)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		if !send(LogLine{Pod: pod, Line: scanner.Text()}) {
+			return
+		}
+	}
+
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		send(LogLine{Pod: pod, Error: err.Error()})
+	}
+}
@@ -0,0 +1,126 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/errors"
+	"github.com/injunweb/backend-server/pkg/kubernetes"
+
+	"gorm.io/gorm"
+)
+
+type GetApplicationQuotaResponse struct {
+	Tier   string             `json:"tier"`
+	Limits models.QuotaLimits `json:"limits"`
+	Usage  struct {
+		Hard map[string]string `json:"hard"`
+		Used map[string]string `json:"used"`
+	} `json:"usage"`
+}
+
+func (s *ApplicationService) GetApplicationQuota(userId uint, appId uint) (GetApplicationQuotaResponse, errors.CustomError) {
+	var application models.Application
+	if err := s.db.First(&application, appId).Error; err != nil {
+		return GetApplicationQuotaResponse{}, errors.NotFound("application not found")
+	}
+
+	if err := authorizeApplication(s.db, userId, application, applicationAccessRead); err != nil {
+		return GetApplicationQuotaResponse{}, err
+	}
+
+	response := GetApplicationQuotaResponse{
+		Tier:   application.QuotaTier,
+		Limits: models.QuotaTierLimits(application.QuotaTier),
+	}
+	response.Usage.Hard = map[string]string{}
+	response.Usage.Used = map[string]string{}
+
+	if application.Status != models.ApplicationStatusApproved && application.Status != models.ApplicationStatusSuspended {
+		return response, nil
+	}
+
+	usage, err := kubernetes.GetQuotaUsage(application.Name)
+	if err != nil {
+		return GetApplicationQuotaResponse{}, errors.BadGateway(fmt.Sprintf("failed to read quota usage: %v", err))
+	}
+	response.Usage.Hard = usage.Hard
+	response.Usage.Used = usage.Used
+
+	return response, nil
+}
+
+type UpdateApplicationQuotaRequest struct {
+	Tier string `json:"tier" binding:"required"`
+}
+
+type UpdateApplicationQuotaByAdminResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *AdminService) UpdateApplicationQuotaByAdmin(actorId uint, appId uint, req UpdateApplicationQuotaRequest) (UpdateApplicationQuotaByAdminResponse, errors.CustomError) {
+	if err := authorize(s.db, actorId, models.PermissionApplicationsManage); err != nil {
+		return UpdateApplicationQuotaByAdminResponse{}, err
+	}
+
+	if !models.IsValidQuotaTier(req.Tier) {
+		return UpdateApplicationQuotaByAdminResponse{}, errors.BadRequest("invalid quota tier")
+	}
+
+	var application models.Application
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&application, appId).Error; err != nil {
+			return errors.NotFound("application not found")
+		}
+
+		previous := application.QuotaTier
+		application.QuotaTier = req.Tier
+		if err := tx.Model(&application).Update("quota_tier", application.QuotaTier).Error; err != nil {
+			return errors.Internal("failed to update quota tier")
+		}
+
+		return recordAuditLog(tx, actorId, models.AuditActionApplicationQuota, auditTargetApplication, application.ID, fmt.Sprintf("quota tier of %s changed from %s to %s", application.Name, previous, req.Tier))
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return UpdateApplicationQuotaByAdminResponse{}, customErr
+		}
+		return UpdateApplicationQuotaByAdminResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	// Applications that are not provisioned pick up the tier on approval.
+	if application.Status == models.ApplicationStatusApproved || application.Status == models.ApplicationStatusSuspended {
+		if err := applyApplicationQuota(application); err != nil {
+			return UpdateApplicationQuotaByAdminResponse{}, errors.BadGateway(fmt.Sprintf("failed to apply quota: %v", err))
+		}
+	}
+
+	s.notificationService.CreateNotification(application.OwnerID, fmt.Sprintf("The resource quota of application %s is now %s", application.Name, req.Tier))
+
+	return UpdateApplicationQuotaByAdminResponse{
+		Message: "Quota updated successfully",
+	}, nil
+}
+
+func applyApplicationQuota(application models.Application) error {
+	if err := kubernetes.EnsureNamespace(application.Name); err != nil {
+		return err
+	}
+
+	return kubernetes.ApplyQuota(application.Name, applicationQuotaSpec(application.QuotaTier))
+}
+
+func applicationQuotaSpec(tier string) kubernetes.QuotaSpec {
+	limits := models.QuotaTierLimits(tier)
+	return kubernetes.QuotaSpec{
+		CPU:                  limits.CPU,
+		Memory:               limits.Memory,
+		Pods:                 limits.Pods,
+		Storage:              limits.Storage,
+		DefaultCPU:           limits.DefaultCPU,
+		DefaultMemory:        limits.DefaultMemory,
+		DefaultCPURequest:    limits.DefaultCPURequest,
+		DefaultMemoryRequest: limits.DefaultMemoryRequest,
+	}
+}
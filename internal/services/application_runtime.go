@@ -0,0 +1,199 @@
+package services
+
+import (
+	"fmt"
+	"log"
+
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/errors"
+	"github.com/injunweb/backend-server/pkg/github"
+	"github.com/injunweb/backend-server/pkg/kubernetes"
+
+	"gorm.io/gorm"
+)
+
+type ApplicationRuntimeResponse struct {
+	Replicas int    `json:"replicas"`
+	Stopped  bool   `json:"stopped"`
+	Message  string `json:"message"`
+}
+
+func (s *ApplicationService) RestartApplication(userId uint, appId uint) (ApplicationRuntimeResponse, errors.CustomError) {
+	var application models.Application
+	if err := s.db.First(&application, appId).Error; err != nil {
+		return ApplicationRuntimeResponse{}, errors.NotFound("application not found")
+	}
+
+	if err := authorizeApplication(s.db, userId, application, applicationAccessWrite); err != nil {
+		return ApplicationRuntimeResponse{}, err
+	}
+
+	if application.Status != models.ApplicationStatusApproved {
+		return ApplicationRuntimeResponse{}, errors.BadRequest("application is not running")
+	}
+
+	if application.Stopped {
+		return ApplicationRuntimeResponse{}, errors.BadRequest("application is stopped")
+	}
+
+	if err := kubernetes.RestartDeployments(application.Name); err != nil {
+		return ApplicationRuntimeResponse{}, errors.BadGateway(fmt.Sprintf("failed to restart application: %v", err))
+	}
+
+	if err := recordAuditLog(s.db, userId, models.AuditActionApplicationRestart, auditTargetApplication, application.ID, fmt.Sprintf("%s restarted", application.Name)); err != nil {
+		return ApplicationRuntimeResponse{}, err
+	}
+
+	return ApplicationRuntimeResponse{
+		Replicas: application.Replicas,
+		Stopped:  application.Stopped,
+		Message:  "Application restart started",
+	}, nil
+}
+
+type ScaleApplicationRequest struct {
+	Replicas int `json:"replicas" binding:"required,min=1"`
+}
+
+func (s *ApplicationService) ScaleApplication(userId uint, appId uint, req ScaleApplicationRequest) (ApplicationRuntimeResponse, errors.CustomError) {
+	var application models.Application
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&application, appId).Error; err != nil {
+			return errors.NotFound("application not found")
+		}
+
+		if err := authorizeApplication(tx, userId, application, applicationAccessWrite); err != nil {
+			return err
+		}
+
+		if application.Status != models.ApplicationStatusApproved {
+			return errors.BadRequest("application is not running")
+		}
+
+		// The limit belongs to the owner, whoever does the scaling.
+		var owner models.User
+		if err := tx.First(&owner, application.OwnerID).Error; err != nil {
+			return errors.NotFound("owner not found")
+		}
+		if req.Replicas > owner.MaxReplicas {
+			return errors.BadRequest(fmt.Sprintf("replicas cannot exceed %d", owner.MaxReplicas))
+		}
+
+		// A rolling update runs one extra pod next to the requested ones.
+		fits, err := applicationQuotaSpec(application.QuotaTier).FitsDefaultPods(req.Replicas + 1)
+		if err != nil {
+			return errors.Internal(fmt.Sprintf("failed to check quota: %v", err))
+		}
+		if !fits {
+			return errors.BadRequest(fmt.Sprintf("the %s quota tier has no room for %d replicas", application.QuotaTier, req.Replicas))
+		}
+
+		previous := application.Replicas
+		application.Replicas = req.Replicas
+		if err := tx.Model(&application).Update("replicas", application.Replicas).Error; err != nil {
+			return errors.Internal("failed to update application")
+		}
+
+		if err := applyReplicas(application); err != nil {
+			return err
+		}
+
+		return recordAuditLog(tx, userId, models.AuditActionApplicationScale, auditTargetApplication, application.ID, fmt.Sprintf("%s scaled from %d to %d replicas", application.Name, previous, req.Replicas))
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return ApplicationRuntimeResponse{}, customErr
+		}
+		return ApplicationRuntimeResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	return ApplicationRuntimeResponse{
+		Replicas: application.Replicas,
+		Stopped:  application.Stopped,
+		Message:  "Application scaled successfully",
+	}, nil
+}
+
+func (s *ApplicationService) StopApplication(userId uint, appId uint) (ApplicationRuntimeResponse, errors.CustomError) {
+	return s.setApplicationStopped(userId, appId, true)
+}
+
+func (s *ApplicationService) StartApplication(userId uint, appId uint) (ApplicationRuntimeResponse, errors.CustomError) {
+	return s.setApplicationStopped(userId, appId, false)
+}
+
+func (s *ApplicationService) setApplicationStopped(userId uint, appId uint, stopped bool) (ApplicationRuntimeResponse, errors.CustomError) {
+	var application models.Application
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&application, appId).Error; err != nil {
+			return errors.NotFound("application not found")
+		}
+
+		if err := authorizeApplication(tx, userId, application, applicationAccessWrite); err != nil {
+			return err
+		}
+
+		if application.Status != models.ApplicationStatusApproved {
+			return errors.BadRequest("application is not running")
+		}
+
+		if application.Stopped == stopped {
+			if stopped {
+				return errors.Conflict("application is already stopped")
+			}
+			return errors.Conflict("application is already started")
+		}
+
+		application.Stopped = stopped
+		if err := tx.Model(&application).Update("stopped", application.Stopped).Error; err != nil {
+			return errors.Internal("failed to update application")
+		}
+
+		if err := applyReplicas(application); err != nil {
+			return err
+		}
+
+		action := models.AuditActionApplicationStart
+		if stopped {
+			action = models.AuditActionApplicationStop
+		}
+		return recordAuditLog(tx, userId, action, auditTargetApplication, application.ID, application.Name)
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return ApplicationRuntimeResponse{}, customErr
+		}
+		return ApplicationRuntimeResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	message := "Application started successfully"
+	if stopped {
+		message = "Application stopped successfully"
+	}
+
+	return ApplicationRuntimeResponse{
+		Replicas: application.Replicas,
+		Stopped:  application.Stopped,
+		Message:  message,
+	}, nil
+}
+
+// applyReplicas writes the count to the GitOps values and then scales the
+// running deployment so the change takes effect before the next sync. It runs
+// before tx commits: a failed dispatch leaves the values and the cluster
+// untouched, so rolling back keeps everything consistent. Once the values are
+// written the change is committed to, and a failed scale is only logged since
+// the next sync applies the same count.
+func applyReplicas(application models.Application) errors.CustomError {
+	if err := github.TriggerWriteValuesWorkflow(application); err != nil {
+		return errors.Internal(fmt.Sprintf("failed to trigger GitHub workflow: %v", err))
+	}
+
+	if err := kubernetes.ScaleDeployments(application.Name, int32(application.DesiredReplicas())); err != nil {
+		log.Printf("Failed to scale %s, waiting for the next sync: %v\n", application.Name, err)
+	}
+
+	return nil
+}
@@ -0,0 +1,161 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+type ApplicationStatusReasonRequest struct {
+	Reason string `json:"reason" binding:"required"`
+}
+
+type RejectApplicationByAdminResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *AdminService) RejectApplicationByAdmin(actorId uint, appId uint, req ApplicationStatusReasonRequest) (RejectApplicationByAdminResponse, errors.CustomError) {
+	if err := authorize(s.db, actorId, models.PermissionApplicationsReview); err != nil {
+		return RejectApplicationByAdminResponse{}, err
+	}
+
+	var application models.Application
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&application, appId).Error; err != nil {
+			return errors.NotFound("application not found")
+		}
+
+		if err := transitionApplication(tx, &application, models.ApplicationStatusRejected, req.Reason); err != nil {
+			return err
+		}
+
+		return recordAuditLog(tx, actorId, models.AuditActionApplicationReject, auditTargetApplication, application.ID, req.Reason)
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return RejectApplicationByAdminResponse{}, customErr
+		}
+		return RejectApplicationByAdminResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	s.notificationService.CreateNotification(application.OwnerID, fmt.Sprintf("Your application %s was rejected: %s", application.Name, req.Reason))
+
+	return RejectApplicationByAdminResponse{
+		Message: "Application rejected successfully",
+	}, nil
+}
+
+type SuspendApplicationByAdminResponse struct {
+	JobID   uint   `json:"job_id"`
+	Message string `json:"message"`
+}
+
+func (s *AdminService) SuspendApplicationByAdmin(actorId uint, appId uint, req ApplicationStatusReasonRequest) (SuspendApplicationByAdminResponse, errors.CustomError) {
+	if err := authorize(s.db, actorId, models.PermissionApplicationsManage); err != nil {
+		return SuspendApplicationByAdminResponse{}, err
+	}
+
+	var application models.Application
+	var job models.Job
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&application, appId).Error; err != nil {
+			return errors.NotFound("application not found")
+		}
+
+		if err := transitionApplication(tx, &application, models.ApplicationStatusSuspended, req.Reason); err != nil {
+			return err
+		}
+
+		var err errors.CustomError
+		job, err = s.provisioningService.EnqueueStatusApply(tx, application, actorId)
+		if err != nil {
+			return err
+		}
+
+		return recordAuditLog(tx, actorId, models.AuditActionApplicationSuspend, auditTargetApplication, application.ID, req.Reason)
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return SuspendApplicationByAdminResponse{}, customErr
+		}
+		return SuspendApplicationByAdminResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	s.notificationService.CreateNotification(application.OwnerID, fmt.Sprintf("Your application %s was suspended: %s", application.Name, req.Reason))
+
+	return SuspendApplicationByAdminResponse{
+		JobID:   job.ID,
+		Message: "Application suspension started",
+	}, nil
+}
+
+type ResumeApplicationByAdminResponse struct {
+	JobID   uint   `json:"job_id"`
+	Message string `json:"message"`
+}
+
+func (s *AdminService) ResumeApplicationByAdmin(actorId uint, appId uint) (ResumeApplicationByAdminResponse, errors.CustomError) {
+	if err := authorize(s.db, actorId, models.PermissionApplicationsManage); err != nil {
+		return ResumeApplicationByAdminResponse{}, err
+	}
+
+	var application models.Application
+	var job models.Job
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&application, appId).Error; err != nil {
+			return errors.NotFound("application not found")
+		}
+
+		if err := transitionApplication(tx, &application, models.ApplicationStatusApproved, ""); err != nil {
+			return err
+		}
+
+		var err errors.CustomError
+		job, err = s.provisioningService.EnqueueStatusApply(tx, application, actorId)
+		if err != nil {
+			return err
+		}
+
+		return recordAuditLog(tx, actorId, models.AuditActionApplicationResume, auditTargetApplication, application.ID, "")
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return ResumeApplicationByAdminResponse{}, customErr
+		}
+		return ResumeApplicationByAdminResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	s.notificationService.CreateNotification(application.OwnerID, fmt.Sprintf("Your application %s has been resumed", application.Name))
+
+	return ResumeApplicationByAdminResponse{
+		JobID:   job.ID,
+		Message: "Application resumption started",
+	}, nil
+}
+
+// transitionApplication applies a lifecycle transition and persists it only if
+// nobody else changed the status in the meantime.
+func transitionApplication(tx *gorm.DB, application *models.Application, status string, reason string) errors.CustomError {
+	from := application.Status
+	if err := application.TransitionTo(status, truncate(reason, 1024)); err != nil {
+		return errors.BadRequest(err.Error())
+	}
+
+	result := tx.Model(&models.Application{}).
+		Where("id = ? AND status = ?", application.ID, from).
+		Updates(map[string]interface{}{"status": application.Status, "status_reason": application.StatusReason})
+	if result.Error != nil {
+		return errors.Internal("failed to update application status")
+	}
+	if result.RowsAffected == 0 {
+		return errors.Conflict("application status changed concurrently")
+	}
+
+	return nil
+}
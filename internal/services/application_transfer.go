@@ -0,0 +1,390 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+const applicationTransferTTL = 7 * 24 * time.Hour
+
+type RequestApplicationTransferRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+type RequestApplicationTransferResponse struct {
+	TransferID uint   `json:"transfer_id"`
+	ExpiresAt  string `json:"expires_at"`
+	Message    string `json:"message"`
+}
+
+// RequestApplicationTransfer offers the application to another user. Once the
+// transfer is accepted the database password is rotated, written to the
+// application's Vault secret as DB_PASSWORD and the workloads are restarted;
+// applications must read the password from that key to keep their connection.
+func (s *ApplicationService) RequestApplicationTransfer(userId uint, appId uint, req RequestApplicationTransferRequest) (RequestApplicationTransferResponse, errors.CustomError) {
+	var application models.Application
+	var recipient models.User
+	var owner models.User
+	var transfer models.ApplicationTransfer
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&application, appId).Error; err != nil {
+			return errors.NotFound("application not found")
+		}
+
+		// Ownership is personal, so only the current owner can give it away;
+		// staff use the admin override instead.
+		if !ownsApplication(tx, userId, application) {
+			return errors.Forbidden("only the owner can transfer an application")
+		}
+
+		if err := tx.First(&owner, userId).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		if err := tx.Where("username = ?", req.Username).First(&recipient).Error; err != nil {
+			return errors.NotFound("recipient not found")
+		}
+
+		if recipient.ID == userId {
+			return errors.BadRequest("cannot transfer an application to yourself")
+		}
+
+		if !recipient.IsEmailVerified() {
+			return errors.BadRequest("recipient email address not verified")
+		}
+
+		if err := requireTransferRecipientMember(tx, application, recipient.ID); err != nil {
+			return err
+		}
+
+		if err := cancelPendingTransfers(tx, application.ID); err != nil {
+			return err
+		}
+
+		transfer = models.ApplicationTransfer{
+			ApplicationID: application.ID,
+			FromUserID:    userId,
+			ToUserID:      recipient.ID,
+			Status:        models.ApplicationTransferStatusPending,
+			ExpiresAt:     time.Now().Add(applicationTransferTTL),
+		}
+		if err := tx.Create(&transfer).Error; err != nil {
+			return errors.Internal("failed to create transfer")
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return RequestApplicationTransferResponse{}, customErr
+		}
+		return RequestApplicationTransferResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	s.notificationService.CreateNotification(recipient.ID, fmt.Sprintf("%s wants to transfer application %s to you", owner.Username, application.Name))
+
+	return RequestApplicationTransferResponse{
+		TransferID: transfer.ID,
+		ExpiresAt:  transfer.ExpiresAt.Format("2006-01-02 15:04:05"),
+		Message:    "Transfer requested, waiting for the recipient to accept",
+	}, nil
+}
+
+type CancelApplicationTransferResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *ApplicationService) CancelApplicationTransfer(userId uint, appId uint) (CancelApplicationTransferResponse, errors.CustomError) {
+	var application models.Application
+	if err := s.db.First(&application, appId).Error; err != nil {
+		return CancelApplicationTransferResponse{}, errors.NotFound("application not found")
+	}
+
+	if !ownsApplication(s.db, userId, application) {
+		return CancelApplicationTransferResponse{}, errors.Forbidden("only the owner can cancel a transfer")
+	}
+
+	var transfer models.ApplicationTransfer
+	if err := s.db.Where("application_id = ? AND status = ?", appId, models.ApplicationTransferStatusPending).First(&transfer).Error; err != nil {
+		return CancelApplicationTransferResponse{}, errors.NotFound("no pending transfer")
+	}
+
+	if err := cancelPendingTransfers(s.db, application.ID); err != nil {
+		return CancelApplicationTransferResponse{}, err
+	}
+
+	s.notificationService.CreateNotification(transfer.ToUserID, fmt.Sprintf("The transfer of application %s was cancelled", application.Name))
+
+	return CancelApplicationTransferResponse{
+		Message: "Transfer cancelled",
+	}, nil
+}
+
+type GetApplicationTransfersResponse struct {
+	Transfers []struct {
+		ID              uint   `json:"id"`
+		ApplicationID   uint   `json:"application_id"`
+		ApplicationName string `json:"application_name"`
+		FromUsername    string `json:"from_username"`
+		ExpiresAt       string `json:"expires_at"`
+	} `json:"transfers"`
+}
+
+func (s *ApplicationService) GetApplicationTransfers(userId uint) (GetApplicationTransfersResponse, errors.CustomError) {
+	var transfers []models.ApplicationTransfer
+	err := s.db.Preload("Application").Preload("FromUser").
+		Where("to_user_id = ? AND status = ? AND expires_at > ?", userId, models.ApplicationTransferStatusPending, time.Now()).
+		Find(&transfers).Error
+	if err != nil {
+		return GetApplicationTransfersResponse{}, errors.Internal("failed to retrieve transfers")
+	}
+
+	var response GetApplicationTransfersResponse
+	for _, transfer := range transfers {
+		response.Transfers = append(response.Transfers, struct {
+			ID              uint   `json:"id"`
+			ApplicationID   uint   `json:"application_id"`
+			ApplicationName string `json:"application_name"`
+			FromUsername    string `json:"from_username"`
+			ExpiresAt       string `json:"expires_at"`
+		}{
+			ID:              transfer.ID,
+			ApplicationID:   transfer.ApplicationID,
+			ApplicationName: transfer.Application.Name,
+			FromUsername:    transfer.FromUser.Username,
+			ExpiresAt:       transfer.ExpiresAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return response, nil
+}
+
+type RespondApplicationTransferResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *ApplicationService) AcceptApplicationTransfer(userId uint, transferId uint) (RespondApplicationTransferResponse, errors.CustomError) {
+	var transfer models.ApplicationTransfer
+	var recipient models.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := loadPendingTransfer(tx, userId, transferId, &transfer); err != nil {
+			return err
+		}
+
+		if err := tx.First(&recipient, userId).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		if transfer.Application.OwnerID != transfer.FromUserID {
+			return errors.Conflict("application owner changed since the transfer was requested")
+		}
+
+		now := time.Now()
+		transfer.Status = models.ApplicationTransferStatusAccepted
+		transfer.RespondedAt = &now
+		if err := tx.Save(&transfer).Error; err != nil {
+			return errors.Internal("failed to update transfer")
+		}
+
+		return transferApplicationOwnership(tx, s.provisioningService, &transfer.Application, recipient, userId)
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return RespondApplicationTransferResponse{}, customErr
+		}
+		return RespondApplicationTransferResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	s.notificationService.CreateNotification(transfer.FromUserID, fmt.Sprintf("%s accepted ownership of application %s", recipient.Username, transfer.Application.Name))
+	s.notificationService.CreateNotification(recipient.ID, fmt.Sprintf("You are now the owner of application %s", transfer.Application.Name))
+
+	return RespondApplicationTransferResponse{
+		Message: "Transfer accepted",
+	}, nil
+}
+
+func (s *ApplicationService) DeclineApplicationTransfer(userId uint, transferId uint) (RespondApplicationTransferResponse, errors.CustomError) {
+	var transfer models.ApplicationTransfer
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := loadPendingTransfer(tx, userId, transferId, &transfer); err != nil {
+			return err
+		}
+
+		now := time.Now()
+		transfer.Status = models.ApplicationTransferStatusDeclined
+		transfer.RespondedAt = &now
+		if err := tx.Save(&transfer).Error; err != nil {
+			return errors.Internal("failed to update transfer")
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return RespondApplicationTransferResponse{}, customErr
+		}
+		return RespondApplicationTransferResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	s.notificationService.CreateNotification(transfer.FromUserID, fmt.Sprintf("The transfer of application %s was declined", transfer.Application.Name))
+
+	return RespondApplicationTransferResponse{
+		Message: "Transfer declined",
+	}, nil
+}
+
+type TransferApplicationByAdminRequest struct {
+	Username string `json:"username" binding:"required"`
+}
+
+type TransferApplicationByAdminResponse struct {
+	Message string `json:"message"`
+}
+
+// TransferApplicationByAdmin moves the application to another user at once and
+// rotates its database password the same way an accepted transfer does.
+func (s *AdminService) TransferApplicationByAdmin(actorId uint, appId uint, req TransferApplicationByAdminRequest) (TransferApplicationByAdminResponse, errors.CustomError) {
+	if err := authorize(s.db, actorId, models.PermissionApplicationsManage); err != nil {
+		return TransferApplicationByAdminResponse{}, err
+	}
+
+	var application models.Application
+	var recipient models.User
+	var previousOwnerId uint
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&application, appId).Error; err != nil {
+			return errors.NotFound("application not found")
+		}
+
+		if err := tx.Where("username = ?", req.Username).First(&recipient).Error; err != nil {
+			return errors.NotFound("recipient not found")
+		}
+
+		if recipient.ID == application.OwnerID {
+			return errors.BadRequest("user already owns this application")
+		}
+
+		if !recipient.IsEmailVerified() {
+			return errors.BadRequest("recipient email address not verified")
+		}
+
+		previousOwnerId = application.OwnerID
+		if err := transferApplicationOwnership(tx, s.provisioningService, &application, recipient, actorId); err != nil {
+			return err
+		}
+
+		return recordAuditLog(tx, actorId, models.AuditActionApplicationTransfer, auditTargetApplication, application.ID,
+			fmt.Sprintf("ownership of %s moved from user %d to %s", application.Name, previousOwnerId, recipient.Username))
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return TransferApplicationByAdminResponse{}, customErr
+		}
+		return TransferApplicationByAdminResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	s.notificationService.CreateNotification(previousOwnerId, fmt.Sprintf("An administrator transferred application %s to %s", application.Name, recipient.Username))
+	s.notificationService.CreateNotification(recipient.ID, fmt.Sprintf("An administrator made you the owner of application %s", application.Name))
+
+	return TransferApplicationByAdminResponse{
+		Message: "Application transferred successfully",
+	}, nil
+}
+
+// transferApplicationOwnership moves the application to newOwner and, for
+// provisioned applications, queues a rotation of the database password so the
+// previous owner's credentials stop working once tx commits.
+func transferApplicationOwnership(tx *gorm.DB, provisioningService *ProvisioningService, application *models.Application, newOwner models.User, actorId uint) errors.CustomError {
+	if err := requireTransferRecipientMember(tx, *application, newOwner.ID); err != nil {
+		return err
+	}
+
+	application.OwnerID = newOwner.ID
+	if err := tx.Model(application).Update("owner_id", newOwner.ID).Error; err != nil {
+		return errors.Internal("failed to update application owner")
+	}
+
+	if err := tx.Unscoped().Where("application_id = ? AND user_id = ?", application.ID, newOwner.ID).Delete(&models.ApplicationCollaborator{}).Error; err != nil {
+		return errors.Internal("failed to update collaborators")
+	}
+
+	if err := cancelPendingTransfers(tx, application.ID); err != nil {
+		return err
+	}
+
+	if application.Status != models.ApplicationStatusApproved && application.Status != models.ApplicationStatusSuspended {
+		return nil
+	}
+
+	if _, err := provisioningService.EnqueueCredentialRotation(tx, *application, actorId); err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// requireTransferRecipientMember keeps organization applications inside their
+// organization: the new owner must already be a member of it.
+func requireTransferRecipientMember(tx *gorm.DB, application models.Application, userId uint) errors.CustomError {
+	if application.OrganizationID == nil {
+		return nil
+	}
+
+	var count int64
+	tx.Model(&models.OrganizationMember{}).Where("organization_id = ? AND user_id = ?", *application.OrganizationID, userId).Count(&count)
+	if count == 0 {
+		return errors.BadRequest("recipient is not a member of the application's organization")
+	}
+
+	return nil
+}
+
+// cancelMemberApplicationTransfers withdraws the hand-overs a departing member
+// started for the applications they own in the organization, since they no
+// longer control them.
+func cancelMemberApplicationTransfers(tx *gorm.DB, orgId uint, userId uint) errors.CustomError {
+	var applicationIds []uint
+	if err := tx.Model(&models.Application{}).Where("organization_id = ? AND owner_id = ?", orgId, userId).Pluck("id", &applicationIds).Error; err != nil {
+		return errors.Internal("failed to retrieve applications")
+	}
+
+	for _, applicationId := range applicationIds {
+		if err := cancelPendingTransfers(tx, applicationId); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func cancelPendingTransfers(tx *gorm.DB, appId uint) errors.CustomError {
+	err := tx.Model(&models.ApplicationTransfer{}).
+		Where("application_id = ? AND status = ?", appId, models.ApplicationTransferStatusPending).
+		Updates(map[string]interface{}{"status": models.ApplicationTransferStatusCancelled, "responded_at": time.Now()}).Error
+	if err != nil {
+		return errors.Internal("failed to cancel pending transfers")
+	}
+
+	return nil
+}
+
+func loadPendingTransfer(tx *gorm.DB, userId uint, transferId uint, transfer *models.ApplicationTransfer) errors.CustomError {
+	if err := tx.Preload("Application").Where("id = ? AND to_user_id = ?", transferId, userId).First(transfer).Error; err != nil {
+		return errors.NotFound("transfer not found")
+	}
+
+	if transfer.Status != models.ApplicationTransferStatusPending || time.Now().After(transfer.ExpiresAt) {
+		return errors.BadRequest("transfer is no longer pending")
+	}
+
+	return nil
+}
@@ -0,0 +1,98 @@
+package services
+
+import (
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+const (
+	auditTargetUser        = "user"
+	auditTargetApplication = "application"
+
+	defaultAuditLogLimit = 100
+	maxAuditLogLimit     = 500
+)
+
+type GetAuditLogsByAdminResponse struct {
+	AuditLogs []struct {
+		ID            uint   `json:"id"`
+		ActorID       uint   `json:"actor_id"`
+		ActorUsername string `json:"actor_username"`
+		Action        string `json:"action"`
+		TargetType    string `json:"target_type"`
+		TargetID      uint   `json:"target_id"`
+		Details       string `json:"details"`
+		CreatedAt     string `json:"created_at"`
+	} `json:"audit_logs"`
+}
+
+func (s *AdminService) GetAuditLogsByAdmin(actorId uint, targetType string, targetId uint, limit int) (GetAuditLogsByAdminResponse, errors.CustomError) {
+	if err := authorize(s.db, actorId, models.PermissionAuditRead); err != nil {
+		return GetAuditLogsByAdminResponse{}, err
+	}
+
+	if limit <= 0 {
+		limit = defaultAuditLogLimit
+	}
+	if limit > maxAuditLogLimit {
+		limit = maxAuditLogLimit
+	}
+
+	query := s.db.Unscoped().Preload("Actor", func(db *gorm.DB) *gorm.DB { return db.Unscoped() })
+	if targetType != "" {
+		query = query.Where("target_type = ?", targetType)
+	}
+	if targetId != 0 {
+		query = query.Where("target_id = ?", targetId)
+	}
+
+	var logs []models.AuditLog
+	if err := query.Order("created_at DESC").Limit(limit).Find(&logs).Error; err != nil {
+		return GetAuditLogsByAdminResponse{}, errors.Internal("failed to retrieve audit logs")
+	}
+
+	var response GetAuditLogsByAdminResponse
+	for _, log := range logs {
+		response.AuditLogs = append(response.AuditLogs, struct {
+			ID            uint   `json:"id"`
+			ActorID       uint   `json:"actor_id"`
+			ActorUsername string `json:"actor_username"`
+			Action        string `json:"action"`
+			TargetType    string `json:"target_type"`
+			TargetID      uint   `json:"target_id"`
+			Details       string `json:"details"`
+			CreatedAt     string `json:"created_at"`
+		}{
+			ID:            log.ID,
+			ActorID:       log.ActorID,
+			ActorUsername: log.Actor.Username,
+			Action:        log.Action,
+			TargetType:    log.TargetType,
+			TargetID:      log.TargetID,
+			Details:       log.Details,
+			CreatedAt:     log.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return response, nil
+}
+
+// recordAuditLog runs inside the caller's transaction so the audit entry is
+// committed or rolled back together with the change it describes.
+func recordAuditLog(tx *gorm.DB, actorId uint, action string, targetType string, targetId uint, details string) errors.CustomError {
+	log := models.AuditLog{
+		ActorID:    actorId,
+		Action:     action,
+		TargetType: targetType,
+		TargetID:   targetId,
+		Details:    details,
+	}
+
+	if err := tx.Create(&log).Error; err != nil {
+		return errors.Internal("failed to record audit log")
+	}
+
+	return nil
+}
@@ -2,11 +2,17 @@ package services
 
 import (
 	"fmt"
+	"log"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/injunweb/backend-server/internal/config"
 	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/email"
 	"github.com/injunweb/backend-server/pkg/errors"
+	"github.com/injunweb/backend-server/pkg/signing"
+	"github.com/injunweb/backend-server/pkg/token"
 	"github.com/injunweb/backend-server/pkg/validator"
 
 	"github.com/golang-jwt/jwt"
@@ -14,13 +20,27 @@ import (
 	"gorm.io/gorm"
 )
 
+const (
+	defaultAccessTokenHours  = 1
+	defaultRefreshTokenHours = 24 * 30
+	passwordResetTTL         = 30 * time.Minute
+	emailVerificationTTL     = 24 * time.Hour
+	defaultDashboardURL      = "https://dashboard.injunweb.com"
+	twoFactorChallengeTTL    = 5 * time.Minute
+
+	tokenTypeAccess             = "access"
+	tokenTypeTwoFactorChallenge = "2fa_challenge"
+	tokenTypeGithubState        = "github_state"
+)
+
 type AuthService struct {
 	db                  *gorm.DB
 	notificationService *NotificationService
+	loginLimiter        *LoginLimiter
 }
 
-func NewAuthService(db *gorm.DB, notificationService *NotificationService) *AuthService {
-	return &AuthService{db: db, notificationService: notificationService}
+func NewAuthService(db *gorm.DB, notificationService *NotificationService, loginLimiter *LoginLimiter) *AuthService {
+	return &AuthService{db: db, notificationService: notificationService, loginLimiter: loginLimiter}
 }
 
 type LoginRequest struct {
@@ -29,24 +49,42 @@ type LoginRequest struct {
 }
 
 type LoginResponse struct {
-	Token   string `json:"token"`
-	Message string `json:"message"`
+	Token             string `json:"token,omitempty"`
+	RefreshToken      string `json:"refresh_token,omitempty"`
+	ExpiresIn         int64  `json:"expires_in,omitempty"`
+	TwoFactorRequired bool   `json:"two_factor_required,omitempty"`
+	ChallengeToken    string `json:"challenge_token,omitempty"`
+	Message           string `json:"message"`
 }
 
-func (s *AuthService) Login(req LoginRequest) (LoginResponse, errors.CustomError) {
-	var user models.User
+func (s *AuthService) Login(req LoginRequest, ipAddress string, userAgent string) (LoginResponse, errors.CustomError) {
+	if err := s.loginLimiter.Check(req.Username, ipAddress); err != nil {
+		return LoginResponse{}, err
+	}
+
+	var response LoginResponse
+	invalidCredentials := false
 	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
 		if err := tx.Where("username = ?", req.Username).First(&user).Error; err != nil {
+			invalidCredentials = true
 			return errors.Unauthorized("invalid credentials")
 		}
 
 		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+			invalidCredentials = true
 			return errors.Unauthorized("invalid credentials")
 		}
 
-		return nil
+		var customErr errors.CustomError
+		response, customErr = s.completeLogin(tx, user, ipAddress, userAgent)
+		return customErr
 	})
 
+	if invalidCredentials {
+		s.recordLoginFailure(req.Username, ipAddress)
+	}
+
 	if err != nil {
 		if customErr, ok := err.(errors.CustomError); ok {
 			return LoginResponse{}, customErr
@@ -54,20 +92,195 @@ func (s *AuthService) Login(req LoginRequest) (LoginResponse, errors.CustomError
 		return LoginResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
 	}
 
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
-		"user_id":  user.ID,
-		"is_admin": user.IsAdmin,
-		"exp":      time.Now().Add(time.Hour * 24).Unix(),
+	if !response.TwoFactorRequired {
+		s.loginLimiter.Succeed(req.Username)
+	}
+
+	return response, nil
+}
+
+func (s *AuthService) recordLoginFailure(username string, ipAddress string) {
+	failures, locked := s.loginLimiter.Fail(username, ipAddress)
+	if !locked {
+		return
+	}
+
+	var count int64
+	if err := s.db.Model(&models.User{}).Where("username = ?", username).Count(&count).Error; err != nil || count == 0 {
+		return
+	}
+
+	s.notificationService.CreateAdminNotification(fmt.Sprintf("Account %s locked after %d failed login attempts (last from %s)", username, failures, ipAddress))
+}
+
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token" binding:"required"`
+}
+
+type RefreshTokenResponse struct {
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	ExpiresIn    int64  `json:"expires_in"`
+	Message      string `json:"message"`
+}
+
+func (s *AuthService) RefreshToken(req RefreshTokenRequest) (RefreshTokenResponse, errors.CustomError) {
+	var response RefreshTokenResponse
+	reused := false
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		hash := token.Hash(req.RefreshToken)
+
+		var session models.Session
+		if err := tx.Where("refresh_token_hash = ?", hash).First(&session).Error; err != nil {
+			if err != gorm.ErrRecordNotFound {
+				return errors.Internal("failed to retrieve session")
+			}
+
+			// A rotated-out refresh token being presented again means it was copied;
+			// revoke the whole session so neither party can keep using it.
+			if err := tx.Where("previous_refresh_token_hash = ?", hash).First(&session).Error; err != nil {
+				return errors.Unauthorized("invalid refresh token")
+			}
+			if err := revokeSession(tx, &session); err != nil {
+				return err
+			}
+			reused = true
+			return nil
+		}
+
+		if !session.IsActive(time.Now()) {
+			return errors.Unauthorized("session expired or revoked")
+		}
+
+		var user models.User
+		if err := tx.First(&user, session.UserID).Error; err != nil {
+			return errors.Unauthorized("user not found")
+		}
+
+		refreshToken, err := token.Generate(32)
+		if err != nil {
+			return errors.Internal("failed to generate refresh token")
+		}
+
+		session.PreviousRefreshTokenHash = session.RefreshTokenHash
+		session.RefreshTokenHash = token.Hash(refreshToken)
+		session.ExpiresAt = time.Now().Add(refreshTokenTTL())
+		if err := tx.Save(&session).Error; err != nil {
+			return errors.Internal("failed to rotate refresh token")
+		}
+
+		accessToken, err := signAccessToken(user, session)
+		if err != nil {
+			return err
+		}
+
+		response = RefreshTokenResponse{
+			Token:        accessToken,
+			RefreshToken: refreshToken,
+			ExpiresIn:    int64(accessTokenTTL().Seconds()),
+			Message:      "Token refreshed successfully",
+		}
+		return nil
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return RefreshTokenResponse{}, customErr
+		}
+		return RefreshTokenResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	if reused {
+		return RefreshTokenResponse{}, errors.Unauthorized("refresh token reuse detected, session revoked")
+	}
+
+	return response, nil
+}
+
+type LogoutResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *AuthService) Logout(userId uint, sessionId uint) (LogoutResponse, errors.CustomError) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var session models.Session
+		if err := tx.Where("id = ? AND user_id = ?", sessionId, userId).First(&session).Error; err != nil {
+			return errors.NotFound("session not found")
+		}
+
+		return revokeSession(tx, &session)
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return LogoutResponse{}, customErr
+		}
+		return LogoutResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	return LogoutResponse{
+		Message: "Logout successful",
+	}, nil
+}
+
+func (s *AuthService) LogoutAll(userId uint) (LogoutResponse, errors.CustomError) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := revokeUserSessions(tx, userId, 0); err != nil {
+			return err
+		}
+
+		return revokePersonalAccessTokens(tx, userId)
 	})
 
-	tokenString, err := token.SignedString([]byte(config.AppConfig.JWTSecret))
 	if err != nil {
-		return LoginResponse{}, errors.Internal("failed to generate token")
+		if customErr, ok := err.(errors.CustomError); ok {
+			return LogoutResponse{}, customErr
+		}
+		return LogoutResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
 	}
 
-	return LoginResponse{
-		Token:   tokenString,
-		Message: "Login successful",
+	return LogoutResponse{
+		Message: "All sessions have been logged out",
+	}, nil
+}
+
+type AccessTokenClaims struct {
+	UserID    uint
+	SessionID uint
+	Role      string
+	Scopes    []string
+}
+
+func (s *AuthService) ValidateAccessToken(tokenString string) (AccessTokenClaims, errors.CustomError) {
+	claims, customErr := parseClaims(tokenString, tokenTypeAccess)
+	if customErr != nil {
+		return AccessTokenClaims{}, customErr
+	}
+
+	userId, ok := claims["user_id"].(float64)
+	if !ok {
+		return AccessTokenClaims{}, errors.Unauthorized("invalid token claims")
+	}
+	sessionId, ok := claims["session_id"].(float64)
+	if !ok {
+		return AccessTokenClaims{}, errors.Unauthorized("invalid token claims")
+	}
+	role, _ := claims["role"].(string)
+
+	var session models.Session
+	if err := s.db.First(&session, uint(sessionId)).Error; err != nil {
+		return AccessTokenClaims{}, errors.Unauthorized("session not found")
+	}
+
+	if session.UserID != uint(userId) || !session.IsActive(time.Now()) {
+		return AccessTokenClaims{}, errors.Unauthorized("session expired or revoked")
+	}
+
+	return AccessTokenClaims{
+		UserID:    uint(userId),
+		SessionID: uint(sessionId),
+		Role:      role,
 	}, nil
 }
 
@@ -94,6 +307,8 @@ func (s *AuthService) Register(req RegisterRequest) (RegisterResponse, errors.Cu
 		return RegisterResponse{}, errors.BadRequest("invalid password")
 	}
 
+	var user models.User
+	var verificationToken string
 	err := s.db.Transaction(func(tx *gorm.DB) error {
 		var existingUser models.User
 		if err := tx.Where("username = ?", req.Username).First(&existingUser).Error; err == nil {
@@ -105,17 +320,23 @@ func (s *AuthService) Register(req RegisterRequest) (RegisterResponse, errors.Cu
 			return errors.Internal("failed to hash password")
 		}
 
-		user := models.User{
+		user = models.User{
 			Username: req.Username,
 			Email:    req.Email,
 			Password: string(hashedPassword),
-			IsAdmin:  false,
+			Role:     models.RoleUser,
 		}
 
 		if err := tx.Create(&user).Error; err != nil {
 			return errors.Internal("failed to register user")
 		}
 
+		var customErr errors.CustomError
+		verificationToken, customErr = createEmailVerificationToken(tx, user)
+		if customErr != nil {
+			return customErr
+		}
+
 		s.notificationService.CreateAdminNotification("New user registered: " + user.Username)
 		return nil
 	})
@@ -127,7 +348,457 @@ func (s *AuthService) Register(req RegisterRequest) (RegisterResponse, errors.Cu
 		return RegisterResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
 	}
 
+	sendVerificationEmail(user, verificationToken)
+
 	return RegisterResponse{
-		Message: "User registered successfully",
+		Message: "User registered successfully, please check your email to verify your address",
+	}, nil
+}
+
+type VerifyEmailRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type VerifyEmailResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *AuthService) VerifyEmail(req VerifyEmailRequest) (VerifyEmailResponse, errors.CustomError) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var verificationToken models.EmailVerificationToken
+		if err := tx.Where("token_hash = ?", token.Hash(req.Token)).First(&verificationToken).Error; err != nil {
+			return errors.BadRequest("invalid or expired verification token")
+		}
+
+		now := time.Now()
+		if verificationToken.UsedAt != nil || now.After(verificationToken.ExpiresAt) {
+			return errors.BadRequest("invalid or expired verification token")
+		}
+
+		var user models.User
+		if err := tx.First(&user, verificationToken.UserID).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		if user.Email != verificationToken.Email {
+			return errors.BadRequest("email address has changed since this link was sent")
+		}
+
+		user.EmailVerifiedAt = &now
+		if err := tx.Save(&user).Error; err != nil {
+			return errors.Internal("failed to verify email")
+		}
+
+		verificationToken.UsedAt = &now
+		if err := tx.Save(&verificationToken).Error; err != nil {
+			return errors.Internal("failed to consume verification token")
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return VerifyEmailResponse{}, customErr
+		}
+		return VerifyEmailResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	return VerifyEmailResponse{
+		Message: "Email verified successfully",
+	}, nil
+}
+
+type RequestPasswordResetRequest struct {
+	Email string `json:"email" binding:"required"`
+}
+
+type RequestPasswordResetResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *AuthService) RequestPasswordReset(req RequestPasswordResetRequest) (RequestPasswordResetResponse, errors.CustomError) {
+	response := RequestPasswordResetResponse{
+		Message: "If an account with that email exists, a password reset link has been sent",
+	}
+
+	var user models.User
+	if err := s.db.Where("email = ?", req.Email).First(&user).Error; err != nil {
+		if err == gorm.ErrRecordNotFound {
+			return response, nil
+		}
+		return RequestPasswordResetResponse{}, errors.Internal("failed to retrieve user")
+	}
+
+	resetToken, err := token.Generate(32)
+	if err != nil {
+		return RequestPasswordResetResponse{}, errors.Internal("failed to generate reset token")
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&models.PasswordResetToken{}).Error; err != nil {
+			return errors.Internal("failed to invalidate previous reset tokens")
+		}
+
+		resetTokenRecord := models.PasswordResetToken{
+			UserID:    user.ID,
+			TokenHash: token.Hash(resetToken),
+			ExpiresAt: time.Now().Add(passwordResetTTL),
+		}
+
+		if err := tx.Create(&resetTokenRecord).Error; err != nil {
+			return errors.Internal("failed to create reset token")
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return RequestPasswordResetResponse{}, customErr
+		}
+		return RequestPasswordResetResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	resetLink := fmt.Sprintf("%s/reset-password?token=%s", dashboardURL(), resetToken)
+	if err := email.SendPasswordResetEmail(user.Email, resetLink, int(passwordResetTTL.Minutes())); err != nil {
+		log.Printf("Failed to send password reset email to user %d: %v\n", user.ID, err)
+	}
+
+	return response, nil
+}
+
+type ConfirmPasswordResetRequest struct {
+	Token    string `json:"token" binding:"required"`
+	Password string `json:"password" binding:"required"`
+}
+
+type ConfirmPasswordResetResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *AuthService) ConfirmPasswordReset(req ConfirmPasswordResetRequest) (ConfirmPasswordResetResponse, errors.CustomError) {
+	if !validator.IsValidPassword(req.Password) {
+		return ConfirmPasswordResetResponse{}, errors.BadRequest("invalid password")
+	}
+
+	var user models.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var resetToken models.PasswordResetToken
+		if err := tx.Where("token_hash = ?", token.Hash(req.Token)).First(&resetToken).Error; err != nil {
+			return errors.BadRequest("invalid or expired reset token")
+		}
+
+		now := time.Now()
+		if resetToken.UsedAt != nil || now.After(resetToken.ExpiresAt) {
+			return errors.BadRequest("invalid or expired reset token")
+		}
+
+		if err := tx.First(&user, resetToken.UserID).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.Password), bcrypt.DefaultCost)
+		if err != nil {
+			return errors.Internal("failed to hash password")
+		}
+
+		user.Password = string(hashedPassword)
+		if err := tx.Save(&user).Error; err != nil {
+			return errors.Internal("failed to update password")
+		}
+
+		resetToken.UsedAt = &now
+		if err := tx.Save(&resetToken).Error; err != nil {
+			return errors.Internal("failed to consume reset token")
+		}
+
+		if err := revokeUserSessions(tx, user.ID, 0); err != nil {
+			return err
+		}
+
+		return revokePersonalAccessTokens(tx, user.ID)
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return ConfirmPasswordResetResponse{}, customErr
+		}
+		return ConfirmPasswordResetResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	s.notificationService.CreateNotification(user.ID, "Your password has been reset")
+
+	return ConfirmPasswordResetResponse{
+		Message: "Password reset successfully",
+	}, nil
+}
+
+type ChangePasswordRequest struct {
+	CurrentPassword string `json:"current_password" binding:"required"`
+	NewPassword     string `json:"new_password" binding:"required"`
+}
+
+type ChangePasswordResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *AuthService) ChangePassword(userId uint, sessionId uint, req ChangePasswordRequest) (ChangePasswordResponse, errors.CustomError) {
+	if !validator.IsValidPassword(req.NewPassword) {
+		return ChangePasswordResponse{}, errors.BadRequest("invalid password")
+	}
+
+	var user models.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&user, userId).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.CurrentPassword)); err != nil {
+			return errors.Unauthorized("current password is incorrect")
+		}
+
+		if req.CurrentPassword == req.NewPassword {
+			return errors.BadRequest("new password must be different from the current password")
+		}
+
+		hashedPassword, err := bcrypt.GenerateFromPassword([]byte(req.NewPassword), bcrypt.DefaultCost)
+		if err != nil {
+			return errors.Internal("failed to hash password")
+		}
+
+		user.Password = string(hashedPassword)
+		if err := tx.Save(&user).Error; err != nil {
+			return errors.Internal("failed to update password")
+		}
+
+		if err := revokeUserSessions(tx, user.ID, sessionId); err != nil {
+			return err
+		}
+
+		return revokePersonalAccessTokens(tx, user.ID)
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return ChangePasswordResponse{}, customErr
+		}
+		return ChangePasswordResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	s.notificationService.CreateNotification(user.ID, "Your password was changed")
+	if err := email.SendPasswordChangedEmail(user.Email, user.Username); err != nil {
+		log.Printf("Failed to send password changed email to user %d: %v\n", user.ID, err)
+	}
+
+	return ChangePasswordResponse{
+		Message: "Password changed successfully",
+	}, nil
+}
+
+// completeLogin finishes a first-factor login, either issuing a session or a
+// two-factor challenge when the user has TOTP enabled.
+func (s *AuthService) completeLogin(tx *gorm.DB, user models.User, ipAddress string, userAgent string) (LoginResponse, errors.CustomError) {
+	if user.TOTPEnabled {
+		challengeToken, err := signChallengeToken(user)
+		if err != nil {
+			return LoginResponse{}, err
+		}
+
+		return LoginResponse{
+			TwoFactorRequired: true,
+			ChallengeToken:    challengeToken,
+			Message:           "Two-factor authentication required",
+		}, nil
+	}
+
+	accessToken, refreshToken, err := s.createSession(tx, user, ipAddress, userAgent)
+	if err != nil {
+		return LoginResponse{}, err
+	}
+
+	return LoginResponse{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresIn:    int64(accessTokenTTL().Seconds()),
+		Message:      "Login successful",
 	}, nil
 }
+
+func (s *AuthService) GetJWKS() signing.JSONWebKeySet {
+	return signing.PublicKeySet()
+}
+
+func (s *AuthService) createSession(tx *gorm.DB, user models.User, ipAddress string, userAgent string) (string, string, errors.CustomError) {
+	refreshToken, err := token.Generate(32)
+	if err != nil {
+		return "", "", errors.Internal("failed to generate refresh token")
+	}
+
+	if len(userAgent) > 255 {
+		userAgent = userAgent[:255]
+	}
+
+	session := models.Session{
+		UserID:           user.ID,
+		RefreshTokenHash: token.Hash(refreshToken),
+		ExpiresAt:        time.Now().Add(refreshTokenTTL()),
+		IPAddress:        ipAddress,
+		UserAgent:        userAgent,
+	}
+
+	if err := tx.Create(&session).Error; err != nil {
+		return "", "", errors.Internal("failed to create session")
+	}
+
+	accessToken, customErr := signAccessToken(user, session)
+	if customErr != nil {
+		return "", "", customErr
+	}
+
+	return accessToken, refreshToken, nil
+}
+
+func signAccessToken(user models.User, session models.Session) (string, errors.CustomError) {
+	now := time.Now()
+	return signClaims(jwt.MapClaims{
+		"type":       tokenTypeAccess,
+		"user_id":    user.ID,
+		"session_id": session.ID,
+		"role":       user.Role,
+		"iat":        now.Unix(),
+		"exp":        now.Add(accessTokenTTL()).Unix(),
+	})
+}
+
+func signChallengeToken(user models.User) (string, errors.CustomError) {
+	now := time.Now()
+	return signClaims(jwt.MapClaims{
+		"type":    tokenTypeTwoFactorChallenge,
+		"user_id": user.ID,
+		"iat":     now.Unix(),
+		"exp":     now.Add(twoFactorChallengeTTL).Unix(),
+	})
+}
+
+func signClaims(claims jwt.MapClaims) (string, errors.CustomError) {
+	tokenString, err := signing.Sign(claims)
+	if err != nil {
+		return "", errors.Internal("failed to generate token")
+	}
+
+	return tokenString, nil
+}
+
+func parseClaims(tokenString string, tokenType string) (jwt.MapClaims, errors.CustomError) {
+	parsed, err := signing.Parse(tokenString)
+	if err != nil {
+		return nil, errors.Unauthorized(err.Error())
+	}
+
+	claims, ok := parsed.Claims.(jwt.MapClaims)
+	if !ok || !parsed.Valid {
+		return nil, errors.Unauthorized("invalid token")
+	}
+
+	if claimType, _ := claims["type"].(string); claimType != tokenType {
+		return nil, errors.Unauthorized("invalid token type")
+	}
+
+	return claims, nil
+}
+
+func revokeSession(tx *gorm.DB, session *models.Session) errors.CustomError {
+	if session.RevokedAt != nil {
+		return nil
+	}
+
+	now := time.Now()
+	session.RevokedAt = &now
+	if err := tx.Save(session).Error; err != nil {
+		return errors.Internal("failed to revoke session")
+	}
+
+	return nil
+}
+
+// revokeUserSessions revokes every active session of the user except keepSessionId,
+// which may be zero to revoke them all.
+func revokeUserSessions(tx *gorm.DB, userId uint, keepSessionId uint) errors.CustomError {
+	query := tx.Model(&models.Session{}).Where("user_id = ? AND revoked_at IS NULL", userId)
+	if keepSessionId != 0 {
+		query = query.Where("id <> ?", keepSessionId)
+	}
+
+	if err := query.Update("revoked_at", time.Now()).Error; err != nil {
+		return errors.Internal("failed to revoke sessions")
+	}
+
+	return nil
+}
+
+// revokePersonalAccessTokens deletes every personal access token of the user so
+// that credentials issued before a password change stop working.
+func revokePersonalAccessTokens(tx *gorm.DB, userId uint) errors.CustomError {
+	if err := tx.Where("user_id = ?", userId).Delete(&models.PersonalAccessToken{}).Error; err != nil {
+		return errors.Internal("failed to revoke personal access tokens")
+	}
+
+	return nil
+}
+
+func createEmailVerificationToken(tx *gorm.DB, user models.User) (string, errors.CustomError) {
+	verificationToken, err := token.Generate(32)
+	if err != nil {
+		return "", errors.Internal("failed to generate verification token")
+	}
+
+	if err := tx.Where("user_id = ? AND used_at IS NULL", user.ID).Delete(&models.EmailVerificationToken{}).Error; err != nil {
+		return "", errors.Internal("failed to invalidate previous verification tokens")
+	}
+
+	record := models.EmailVerificationToken{
+		UserID:    user.ID,
+		Email:     user.Email,
+		TokenHash: token.Hash(verificationToken),
+		ExpiresAt: time.Now().Add(emailVerificationTTL),
+	}
+
+	if err := tx.Create(&record).Error; err != nil {
+		return "", errors.Internal("failed to create verification token")
+	}
+
+	return verificationToken, nil
+}
+
+func sendVerificationEmail(user models.User, verificationToken string) {
+	verificationLink := fmt.Sprintf("%s/verify-email?token=%s", dashboardURL(), verificationToken)
+	if err := email.SendVerificationEmail(user.Email, verificationLink, int(emailVerificationTTL.Hours())); err != nil {
+		log.Printf("Failed to send verification email to user %d: %v\n", user.ID, err)
+	}
+}
+
+func accessTokenTTL() time.Duration {
+	return hoursFromConfig(config.AppConfig.JWTExpiryHours, defaultAccessTokenHours)
+}
+
+func refreshTokenTTL() time.Duration {
+	return hoursFromConfig(config.AppConfig.RefreshTokenHours, defaultRefreshTokenHours)
+}
+
+func hoursFromConfig(value string, fallback int) time.Duration {
+	hours, err := strconv.Atoi(value)
+	if err != nil || hours <= 0 {
+		hours = fallback
+	}
+
+	return time.Duration(hours) * time.Hour
+}
+
+func dashboardURL() string {
+	if config.AppConfig.DashboardURL == "" {
+		return defaultDashboardURL
+	}
+
+	return strings.TrimRight(config.AppConfig.DashboardURL, "/")
+}
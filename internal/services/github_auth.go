@@ -0,0 +1,341 @@
+package services
+
+import (
+	"crypto/subtle"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/injunweb/backend-server/internal/config"
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/errors"
+	"github.com/injunweb/backend-server/pkg/github"
+	"github.com/injunweb/backend-server/pkg/token"
+	"github.com/injunweb/backend-server/pkg/validator"
+
+	"github.com/golang-jwt/jwt"
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const githubStateTTL = 10 * time.Minute
+
+type GithubAuthorizeResponse struct {
+	URL   string `json:"url"`
+	Nonce string `json:"-"`
+}
+
+func (s *AuthService) GithubAuthorize() (GithubAuthorizeResponse, errors.CustomError) {
+	return githubAuthorizeURL(0)
+}
+
+func (s *AuthService) GithubLinkAuthorize(userId uint) (GithubAuthorizeResponse, errors.CustomError) {
+	return githubAuthorizeURL(userId)
+}
+
+type GithubCallbackRequest struct {
+	Code  string `json:"code" binding:"required"`
+	State string `json:"state" binding:"required"`
+	// Nonce is taken from the browser cookie set at authorization time.
+	Nonce string `json:"-"`
+}
+
+func (s *AuthService) GithubLogin(req GithubCallbackRequest, ipAddress string, userAgent string) (LoginResponse, errors.CustomError) {
+	linkUserId, customErr := parseGithubState(req.State, req.Nonce)
+	if customErr != nil {
+		return LoginResponse{}, customErr
+	}
+	if linkUserId != 0 {
+		return LoginResponse{}, errors.BadRequest("state was issued for account linking")
+	}
+
+	githubUser, primaryEmail, customErr := fetchGithubIdentity(req.Code)
+	if customErr != nil {
+		return LoginResponse{}, customErr
+	}
+
+	var response LoginResponse
+	var created *models.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		err := tx.Where("github_id = ?", githubUser.ID).First(&user).Error
+		if err != nil && err != gorm.ErrRecordNotFound {
+			return errors.Internal("failed to retrieve user")
+		}
+
+		if err == gorm.ErrRecordNotFound {
+			if primaryEmail == "" {
+				return errors.BadRequest("GitHub account has no verified primary email")
+			}
+
+			err := tx.Where("email = ?", primaryEmail).First(&user).Error
+			if err != nil && err != gorm.ErrRecordNotFound {
+				return errors.Internal("failed to retrieve user")
+			}
+
+			// Never merge into an existing account by email alone; the owner has
+			// to sign in and link GitHub explicitly.
+			if err == nil {
+				return errors.Conflict("an account with this email already exists, sign in and link GitHub from your account settings")
+			}
+
+			newUser, customErr := createGithubUser(tx, githubUser, primaryEmail)
+			if customErr != nil {
+				return customErr
+			}
+			user = newUser
+			created = &newUser
+
+			githubId := githubUser.ID
+			user.GithubID = &githubId
+		}
+
+		user.GithubLogin = githubUser.Login
+		if err := tx.Save(&user).Error; err != nil {
+			return errors.Internal("failed to link GitHub account")
+		}
+
+		var customErr errors.CustomError
+		response, customErr = s.completeLogin(tx, user, ipAddress, userAgent)
+		return customErr
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return LoginResponse{}, customErr
+		}
+		return LoginResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	if created != nil {
+		s.notificationService.CreateAdminNotification("New user registered via GitHub: " + created.Username)
+	}
+
+	return response, nil
+}
+
+type GithubLinkResponse struct {
+	GithubLogin string `json:"github_login"`
+	Message     string `json:"message"`
+}
+
+func (s *AuthService) GithubLink(userId uint, req GithubCallbackRequest) (GithubLinkResponse, errors.CustomError) {
+	linkUserId, customErr := parseGithubState(req.State, req.Nonce)
+	if customErr != nil {
+		return GithubLinkResponse{}, customErr
+	}
+	if linkUserId != userId {
+		return GithubLinkResponse{}, errors.BadRequest("state was not issued for this account")
+	}
+
+	githubUser, _, customErr := fetchGithubIdentity(req.Code)
+	if customErr != nil {
+		return GithubLinkResponse{}, customErr
+	}
+
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userId).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		var existing models.User
+		if err := tx.Where("github_id = ? AND id <> ?", githubUser.ID, userId).First(&existing).Error; err == nil {
+			return errors.Conflict("GitHub account is already linked to another user")
+		}
+
+		githubId := githubUser.ID
+		user.GithubID = &githubId
+		user.GithubLogin = githubUser.Login
+		if err := tx.Save(&user).Error; err != nil {
+			return errors.Internal("failed to link GitHub account")
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return GithubLinkResponse{}, customErr
+		}
+		return GithubLinkResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	s.notificationService.CreateNotification(userId, fmt.Sprintf("GitHub account %s was linked to your account", githubUser.Login))
+
+	return GithubLinkResponse{
+		GithubLogin: githubUser.Login,
+		Message:     "GitHub account linked successfully",
+	}, nil
+}
+
+type GithubUnlinkResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *AuthService) GithubUnlink(userId uint) (GithubUnlinkResponse, errors.CustomError) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userId).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		if user.GithubID == nil {
+			return errors.BadRequest("no GitHub account linked")
+		}
+
+		if err := tx.Model(&user).Updates(map[string]interface{}{"github_id": nil, "github_login": ""}).Error; err != nil {
+			return errors.Internal("failed to unlink GitHub account")
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return GithubUnlinkResponse{}, customErr
+		}
+		return GithubUnlinkResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	return GithubUnlinkResponse{
+		Message: "GitHub account unlinked successfully",
+	}, nil
+}
+
+func githubAuthorizeURL(linkUserId uint) (GithubAuthorizeResponse, errors.CustomError) {
+	if config.AppConfig.GithubOAuthID == "" {
+		return GithubAuthorizeResponse{}, errors.ServiceUnavailable("GitHub sign-in is not configured")
+	}
+
+	nonce, err := token.Generate(16)
+	if err != nil {
+		return GithubAuthorizeResponse{}, errors.Internal("failed to generate state")
+	}
+
+	now := time.Now()
+	state, customErr := signClaims(jwt.MapClaims{
+		"type":         tokenTypeGithubState,
+		"nonce":        nonce,
+		"link_user_id": linkUserId,
+		"iat":          now.Unix(),
+		"exp":          now.Add(githubStateTTL).Unix(),
+	})
+	if customErr != nil {
+		return GithubAuthorizeResponse{}, customErr
+	}
+
+	return GithubAuthorizeResponse{
+		URL:   github.AuthorizeURL(githubRedirectURL(), state),
+		Nonce: nonce,
+	}, nil
+}
+
+// parseGithubState validates the state token and checks that it was issued to
+// the same browser by comparing its nonce against the cookie value.
+func parseGithubState(state string, nonce string) (uint, errors.CustomError) {
+	claims, customErr := parseClaims(state, tokenTypeGithubState)
+	if customErr != nil {
+		return 0, errors.BadRequest("invalid or expired state")
+	}
+
+	stateNonce, _ := claims["nonce"].(string)
+	if nonce == "" || subtle.ConstantTimeCompare([]byte(stateNonce), []byte(nonce)) != 1 {
+		return 0, errors.BadRequest("state was not issued to this browser")
+	}
+
+	linkUserId, _ := claims["link_user_id"].(float64)
+	return uint(linkUserId), nil
+}
+
+func fetchGithubIdentity(code string) (github.OAuthUser, string, errors.CustomError) {
+	accessToken, err := github.ExchangeCode(code, githubRedirectURL())
+	if err != nil {
+		return github.OAuthUser{}, "", errors.Unauthorized(fmt.Sprintf("failed to authenticate with GitHub: %v", err))
+	}
+
+	githubUser, err := github.GetAuthenticatedUser(accessToken)
+	if err != nil {
+		return github.OAuthUser{}, "", errors.BadGateway(fmt.Sprintf("failed to fetch GitHub user: %v", err))
+	}
+
+	primaryEmail, err := github.GetPrimaryVerifiedEmail(accessToken)
+	if err != nil {
+		return github.OAuthUser{}, "", errors.BadGateway(fmt.Sprintf("failed to fetch GitHub emails: %v", err))
+	}
+
+	return githubUser, primaryEmail, nil
+}
+
+func createGithubUser(tx *gorm.DB, githubUser github.OAuthUser, primaryEmail string) (models.User, errors.CustomError) {
+	username, customErr := githubUsername(tx, githubUser.Login)
+	if customErr != nil {
+		return models.User{}, customErr
+	}
+
+	randomPassword, err := token.Generate(32)
+	if err != nil {
+		return models.User{}, errors.Internal("failed to generate password")
+	}
+
+	hashedPassword, err := bcrypt.GenerateFromPassword([]byte(randomPassword), bcrypt.DefaultCost)
+	if err != nil {
+		return models.User{}, errors.Internal("failed to hash password")
+	}
+
+	now := time.Now()
+	user := models.User{
+		Username:        username,
+		Email:           primaryEmail,
+		EmailVerifiedAt: &now,
+		Password:        string(hashedPassword),
+		Role:            models.RoleUser,
+	}
+
+	if err := tx.Create(&user).Error; err != nil {
+		return models.User{}, errors.Internal("failed to register user")
+	}
+
+	return user, nil
+}
+
+// githubUsername reuses the GitHub login when it fits local username rules and
+// is free, falling back to a random "gh" prefixed name otherwise.
+func githubUsername(tx *gorm.DB, login string) (string, errors.CustomError) {
+	candidate := strings.ToLower(login)
+	if validator.IsValidUsername(candidate) {
+		var count int64
+		if err := tx.Unscoped().Model(&models.User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", errors.Internal("failed to check username")
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+	}
+
+	for i := 0; i < 5; i++ {
+		suffix, err := token.Generate(3)
+		if err != nil {
+			return "", errors.Internal("failed to generate username")
+		}
+
+		candidate = "gh" + suffix
+		var count int64
+		if err := tx.Unscoped().Model(&models.User{}).Where("username = ?", candidate).Count(&count).Error; err != nil {
+			return "", errors.Internal("failed to check username")
+		}
+		if count == 0 {
+			return candidate, nil
+		}
+	}
+
+	return "", errors.Conflict("failed to allocate a username")
+}
+
+func githubRedirectURL() string {
+	if config.AppConfig.GithubRedirectURL != "" {
+		return config.AppConfig.GithubRedirectURL
+	}
+
+	return dashboardURL() + "/auth/github/callback"
+}
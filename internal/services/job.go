@@ -0,0 +1,344 @@
+package services
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/injunweb/backend-server/internal/config"
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+// applicationJobSubjectPrefix starts the subject of every job that acts on a
+// single application.
+const applicationJobSubjectPrefix = "application:"
+
+const (
+	defaultJobWorkers     = 4
+	defaultJobMaxAttempts = 5
+	jobPollInterval       = 2 * time.Second
+	jobBaseBackoff        = 30 * time.Second
+	jobMaxBackoff         = 30 * time.Minute
+	// Jobs still running after this long belonged to a worker that died and
+	// are put back on the queue.
+	jobStaleAfter = 30 * time.Minute
+)
+
+type JobHandler struct {
+	Run func(payload []byte) error
+	// OnFailure is called once after the last attempt has failed.
+	OnFailure func(payload []byte, err error)
+	// MaxAttempts overrides the default number of attempts when set.
+	MaxAttempts int
+}
+
+type jobSchedule struct {
+	jobType  string
+	interval time.Duration
+}
+
+type JobService struct {
+	db        *gorm.DB
+	mu        sync.RWMutex
+	handlers  map[string]JobHandler
+	schedules []jobSchedule
+	wake      chan struct{}
+}
+
+func NewJobService(db *gorm.DB) *JobService {
+	return &JobService{
+		db:       db,
+		handlers: make(map[string]JobHandler),
+		wake:     make(chan struct{}, 1),
+	}
+}
+
+func (s *JobService) Register(jobType string, handler JobHandler) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.handlers[jobType] = handler
+}
+
+// Schedule enqueues a job of the given type every interval once the workers
+// are started. The type must also be registered.
+func (s *JobService) Schedule(jobType string, interval time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.schedules = append(s.schedules, jobSchedule{jobType: jobType, interval: interval})
+}
+
+// Enqueue stores a job inside tx so it is only visible once the caller's
+// transaction commits. Only one active job per type and subject is allowed,
+// and only one per application whatever its type, since operations on the
+// same application must not interleave.
+func (s *JobService) Enqueue(tx *gorm.DB, jobType string, subject string, payload interface{}, createdById *uint) (models.Job, errors.CustomError) {
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return models.Job{}, errors.Internal("failed to encode job payload")
+	}
+
+	if subject != "" {
+		query := tx.Model(&models.Job{}).Where("subject = ? AND status IN ?", subject, []string{models.JobStatusQueued, models.JobStatusRunning})
+		if !strings.HasPrefix(subject, applicationJobSubjectPrefix) {
+			query = query.Where("type = ?", jobType)
+		}
+
+		var count int64
+		err := query.Count(&count).Error
+		if err != nil {
+			return models.Job{}, errors.Internal("failed to check jobs")
+		}
+		if count > 0 {
+			return models.Job{}, errors.Conflict("operation already in progress")
+		}
+	}
+
+	maxAttempts := defaultJobMaxAttempts
+	s.mu.RLock()
+	if handler, ok := s.handlers[jobType]; ok && handler.MaxAttempts > 0 {
+		maxAttempts = handler.MaxAttempts
+	}
+	s.mu.RUnlock()
+
+	job := models.Job{
+		Type:        jobType,
+		Subject:     subject,
+		Payload:     string(data),
+		Status:      models.JobStatusQueued,
+		MaxAttempts: maxAttempts,
+		NextRunAt:   time.Now(),
+		CreatedByID: createdById,
+	}
+	if err := tx.Create(&job).Error; err != nil {
+		return models.Job{}, errors.Internal("failed to enqueue job")
+	}
+
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+
+	return job, nil
+}
+
+// Start launches the worker pool. Workers stop when ctx is cancelled.
+func (s *JobService) Start(ctx context.Context) {
+	workers, err := strconv.Atoi(config.AppConfig.JobWorkers)
+	if err != nil || workers <= 0 {
+		workers = defaultJobWorkers
+	}
+
+	s.requeueStaleJobs()
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+				s.requeueStaleJobs()
+			}
+		}
+	}()
+
+	for i := 0; i < workers; i++ {
+		go s.work(ctx)
+	}
+
+	s.mu.RLock()
+	for _, schedule := range s.schedules {
+		go s.runSchedule(ctx, schedule)
+	}
+	s.mu.RUnlock()
+
+	log.Printf("Started %d job workers", workers)
+}
+
+func (s *JobService) runSchedule(ctx context.Context, schedule jobSchedule) {
+	ticker := time.NewTicker(schedule.interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			// A Conflict means the previous run has not finished yet.
+			_, err := s.Enqueue(s.db, schedule.jobType, "schedule", struct{}{}, nil)
+			if err != nil && err.GetStatus() != http.StatusConflict {
+				log.Printf("Failed to schedule %s job: %v", schedule.jobType, err.GetMessage())
+			}
+		}
+	}
+}
+
+func (s *JobService) work(ctx context.Context) {
+	ticker := time.NewTicker(jobPollInterval)
+	defer ticker.Stop()
+
+	for {
+		for {
+			job, ok := s.claim()
+			if !ok {
+				break
+			}
+			s.run(job)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-s.wake:
+		}
+	}
+}
+
+func (s *JobService) claim() (models.Job, bool) {
+	for {
+		var job models.Job
+		err := s.db.Where("status = ? AND next_run_at <= ?", models.JobStatusQueued, time.Now()).
+			Order("next_run_at").First(&job).Error
+		if err != nil {
+			return models.Job{}, false
+		}
+
+		now := time.Now()
+		result := s.db.Model(&models.Job{}).
+			Where("id = ? AND status = ?", job.ID, models.JobStatusQueued).
+			Updates(map[string]interface{}{
+				"status":     models.JobStatusRunning,
+				"attempts":   gorm.Expr("attempts + 1"),
+				"started_at": now,
+			})
+		if result.Error != nil {
+			return models.Job{}, false
+		}
+		// Another worker claimed it first; look for the next one.
+		if result.RowsAffected == 0 {
+			continue
+		}
+
+		job.Status = models.JobStatusRunning
+		job.Attempts++
+		job.StartedAt = &now
+		return job, true
+	}
+}
+
+func (s *JobService) run(job models.Job) {
+	s.mu.RLock()
+	handler, ok := s.handlers[job.Type]
+	s.mu.RUnlock()
+
+	var runErr error
+	if !ok {
+		runErr = fmt.Errorf("no handler registered for job type %s", job.Type)
+		job.Attempts = job.MaxAttempts
+	} else {
+		runErr = s.invoke(handler, job)
+	}
+
+	now := time.Now()
+	updates := map[string]interface{}{}
+	switch {
+	case runErr == nil:
+		updates["status"] = models.JobStatusSucceeded
+		updates["last_error"] = ""
+		updates["finished_at"] = now
+	case job.Attempts >= job.MaxAttempts:
+		updates["status"] = models.JobStatusFailed
+		updates["last_error"] = truncate(runErr.Error(), 1024)
+		updates["finished_at"] = now
+	default:
+		updates["status"] = models.JobStatusQueued
+		updates["last_error"] = truncate(runErr.Error(), 1024)
+		updates["next_run_at"] = now.Add(jobBackoff(job.Attempts))
+	}
+
+	if err := s.db.Model(&models.Job{}).Where("id = ?", job.ID).Updates(updates).Error; err != nil {
+		log.Printf("Failed to update job %d: %v", job.ID, err)
+	}
+
+	if runErr != nil {
+		log.Printf("Job %d (%s) attempt %d failed: %v", job.ID, job.Type, job.Attempts, runErr)
+		if ok && job.Attempts >= job.MaxAttempts && handler.OnFailure != nil {
+			handler.OnFailure([]byte(job.Payload), runErr)
+		}
+	}
+}
+
+func (s *JobService) invoke(handler JobHandler, job models.Job) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = fmt.Errorf("job panicked: %v", r)
+		}
+	}()
+
+	return handler.Run([]byte(job.Payload))
+}
+
+func (s *JobService) requeueStaleJobs() {
+	err := s.db.Model(&models.Job{}).
+		Where("status = ? AND started_at < ?", models.JobStatusRunning, time.Now().Add(-jobStaleAfter)).
+		Update("status", models.JobStatusQueued).Error
+	if err != nil {
+		log.Printf("Failed to requeue stale jobs: %v", err)
+	}
+}
+
+func jobBackoff(attempts int) time.Duration {
+	backoff := jobBaseBackoff
+	for i := 1; i < attempts; i++ {
+		backoff *= 2
+		if backoff >= jobMaxBackoff {
+			return jobMaxBackoff
+		}
+	}
+	return backoff
+}
+
+type GetJobResponse struct {
+	ID          uint   `json:"id"`
+	Type        string `json:"type"`
+	Status      string `json:"status"`
+	Attempts    int    `json:"attempts"`
+	MaxAttempts int    `json:"max_attempts"`
+	LastError   string `json:"last_error"`
+	NextRunAt   string `json:"next_run_at"`
+	CreatedAt   string `json:"created_at"`
+	FinishedAt  string `json:"finished_at"`
+}
+
+func (s *JobService) GetJob(userId uint, jobId uint) (GetJobResponse, errors.CustomError) {
+	var job models.Job
+	if err := s.db.First(&job, jobId).Error; err != nil {
+		return GetJobResponse{}, errors.NotFound("job not found")
+	}
+
+	if job.CreatedByID == nil || *job.CreatedByID != userId {
+		return GetJobResponse{}, errors.NotFound("job not found")
+	}
+
+	return GetJobResponse{
+		ID:          job.ID,
+		Type:        job.Type,
+		Status:      job.Status,
+		Attempts:    job.Attempts,
+		MaxAttempts: job.MaxAttempts,
+		LastError:   job.LastError,
+		NextRunAt:   job.NextRunAt.Format("2006-01-02 15:04:05"),
+		CreatedAt:   job.CreatedAt.Format("2006-01-02 15:04:05"),
+		FinishedAt:  formatOptionalTime(job.FinishedAt),
+	}, nil
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/injunweb/backend-server/internal/config"
+	"github.com/injunweb/backend-server/pkg/errors"
+	"github.com/injunweb/backend-server/pkg/limiter"
+
+	"gorm.io/gorm"
+)
+
+const (
+	LoginLimiterMemory   = "memory"
+	LoginLimiterDatabase = "database"
+)
+
+var (
+	usernameLoginPolicy = limiter.Policy{
+		Threshold:   5,
+		Window:      time.Hour,
+		BaseLockout: 30 * time.Second,
+		MaxLockout:  time.Hour,
+	}
+	ipLoginPolicy = limiter.Policy{
+		Threshold:   20,
+		Window:      time.Hour,
+		BaseLockout: time.Minute,
+		MaxLockout:  time.Hour,
+	}
+)
+
+type LoginLimiter struct {
+	usernames *limiter.Limiter
+	ips       *limiter.Limiter
+}
+
+func NewLoginLimiter(db *gorm.DB) *LoginLimiter {
+	var store limiter.Store
+	if strings.ToLower(config.AppConfig.LoginLimiter) == LoginLimiterDatabase {
+		store = limiter.NewDatabaseStore(db)
+	} else {
+		store = limiter.NewMemoryStore(time.Hour)
+	}
+
+	return &LoginLimiter{
+		usernames: limiter.New(store, usernameLoginPolicy),
+		ips:       limiter.New(store, ipLoginPolicy),
+	}
+}
+
+// Check rejects the attempt while either the username or the client IP is locked.
+func (l *LoginLimiter) Check(username string, ipAddress string) errors.CustomError {
+	now := time.Now()
+	for _, check := range []struct {
+		limiter *limiter.Limiter
+		key     string
+	}{
+		{l.usernames, usernameKey(username)},
+		{l.ips, ipKey(ipAddress)},
+	} {
+		remaining, err := check.limiter.Check(check.key, now)
+		if err != nil {
+			log.Printf("Failed to check login limiter for %s: %v\n", check.key, err)
+			continue
+		}
+		if remaining > 0 {
+			return errors.TooManyRequests(fmt.Sprintf("too many failed login attempts, try again in %d seconds", int(math.Ceil(remaining.Seconds()))))
+		}
+	}
+
+	return nil
+}
+
+// Fail records a failed attempt and reports whether the username was newly locked.
+func (l *LoginLimiter) Fail(username string, ipAddress string) (int, bool) {
+	now := time.Now()
+	if _, _, err := l.ips.Fail(ipKey(ipAddress), now); err != nil {
+		log.Printf("Failed to record login failure for IP %s: %v\n", ipAddress, err)
+	}
+
+	attempt, locked, err := l.usernames.Fail(usernameKey(username), now)
+	if err != nil {
+		log.Printf("Failed to record login failure for user %s: %v\n", username, err)
+		return 0, false
+	}
+
+	return attempt.Failures, locked
+}
+
+func (l *LoginLimiter) Succeed(username string) {
+	if err := l.usernames.Reset(usernameKey(username)); err != nil {
+		log.Printf("Failed to reset login limiter for user %s: %v\n", username, err)
+	}
+}
+
+func (l *LoginLimiter) Unlock(username string) errors.CustomError {
+	if err := l.usernames.Reset(usernameKey(username)); err != nil {
+		return errors.Internal(fmt.Sprintf("failed to unlock account: %v", err))
+	}
+
+	return nil
+}
+
+func usernameKey(username string) string {
+	return "user:" + strings.ToLower(username)
+}
+
+func ipKey(ipAddress string) string {
+	return "ip:" + ipAddress
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/kubernetes"
+
+	"gorm.io/gorm"
+)
+
+const (
+	jobTypeWatchWorkloads = "workloads.watch"
+	workloadWatchInterval = 2 * time.Minute
+)
+
+var workloadProblemMessages = map[string]string{
+	kubernetes.ReasonCrashLoopBackOff: "Application %s: container %s keeps crashing (CrashLoopBackOff in pod %s). Check its logs.",
+	kubernetes.ReasonImagePullBackOff: "Application %s: container %s cannot pull its image (pod %s). Check that the image was built and pushed.",
+	kubernetes.ReasonOOMKilled:        "Application %s: container %s was killed for running out of memory (pod %s).",
+}
+
+// MonitoringService periodically looks for failing containers of running
+// applications and notifies their owners once per problem.
+type MonitoringService struct {
+	db                  *gorm.DB
+	notificationService *NotificationService
+}
+
+func NewMonitoringService(db *gorm.DB, notificationService *NotificationService, jobService *JobService) *MonitoringService {
+	s := &MonitoringService{db: db, notificationService: notificationService}
+
+	jobService.Register(jobTypeWatchWorkloads, JobHandler{Run: s.runWatchJob})
+	jobService.Schedule(jobTypeWatchWorkloads, workloadWatchInterval)
+
+	return s
+}
+
+func (s *MonitoringService) runWatchJob(payload []byte) error {
+	var applications []models.Application
+	if err := s.db.Where("status = ?", models.ApplicationStatusApproved).Find(&applications).Error; err != nil {
+		return fmt.Errorf("failed to retrieve applications: %v", err)
+	}
+
+	for _, application := range applications {
+		if err := s.watchApplication(application); err != nil {
+			log.Printf("Failed to watch workloads of %s: %v", application.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *MonitoringService) watchApplication(application models.Application) error {
+	problems, err := kubernetes.FindContainerProblems(application.Name)
+	if err != nil {
+		return err
+	}
+
+	var alerts []models.WorkloadAlert
+	if err := s.db.Where("application_id = ?", application.ID).Find(&alerts).Error; err != nil {
+		return err
+	}
+
+	active := make(map[string]models.WorkloadAlert)
+	for _, alert := range alerts {
+		active[alert.Container+"/"+alert.Reason] = alert
+	}
+
+	now := time.Now()
+	seen := make(map[string]bool)
+	for _, problem := range problems {
+		key := problem.Container + "/" + problem.Reason
+		if seen[key] {
+			continue
+		}
+		seen[key] = true
+
+		if alert, ok := active[key]; ok {
+			if err := s.db.Model(&alert).Updates(map[string]interface{}{"pod": problem.Pod, "last_seen_at": now}).Error; err != nil {
+				return err
+			}
+			continue
+		}
+
+		alert := models.WorkloadAlert{
+			ApplicationID: application.ID,
+			Container:     problem.Container,
+			Reason:        problem.Reason,
+			Pod:           problem.Pod,
+			LastSeenAt:    now,
+		}
+		if err := s.db.Create(&alert).Error; err != nil {
+			return err
+		}
+
+		s.notificationService.CreateNotification(application.OwnerID, fmt.Sprintf(workloadProblemMessages[problem.Reason], application.Name, problem.Container, problem.Pod))
+	}
+
+	for key, alert := range active {
+		if seen[key] {
+			continue
+		}
+		if err := s.db.Unscoped().Delete(&alert).Error; err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
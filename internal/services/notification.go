@@ -64,7 +64,7 @@ func (s *NotificationService) CreateNotification(userID uint, message string) er
 func (s *NotificationService) CreateAdminNotification(message string) errors.CustomError {
 	err := s.db.Transaction(func(tx *gorm.DB) error {
 		var users []models.User
-		if err := tx.Where("is_admin = ?", true).Find(&users).Error; err != nil {
+		if err := tx.Where("role IN ?", models.RolesWithPermission(models.PermissionApplicationsReview)).Find(&users).Error; err != nil {
 			return errors.Internal("failed to retrieve users")
 		}
 
@@ -0,0 +1,593 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/email"
+	"github.com/injunweb/backend-server/pkg/errors"
+	"github.com/injunweb/backend-server/pkg/token"
+	"github.com/injunweb/backend-server/pkg/validator"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const organizationInvitationTTL = 7 * 24 * time.Hour
+
+type OrganizationService struct {
+	db                  *gorm.DB
+	notificationService *NotificationService
+}
+
+func NewOrganizationService(db *gorm.DB, notificationService *NotificationService) *OrganizationService {
+	return &OrganizationService{db: db, notificationService: notificationService}
+}
+
+type GetOrganizationsResponse struct {
+	Organizations []struct {
+		ID          uint   `json:"id"`
+		Name        string `json:"name"`
+		Description string `json:"description"`
+		Role        string `json:"role"`
+		CreatedAt   string `json:"created_at"`
+	} `json:"organizations"`
+}
+
+func (s *OrganizationService) GetOrganizations(userId uint) (GetOrganizationsResponse, errors.CustomError) {
+	var memberships []models.OrganizationMember
+	if err := s.db.Preload("Organization").Where("user_id = ?", userId).Find(&memberships).Error; err != nil {
+		return GetOrganizationsResponse{}, errors.Internal("failed to retrieve organizations")
+	}
+
+	var response GetOrganizationsResponse
+	for _, membership := range memberships {
+		response.Organizations = append(response.Organizations, struct {
+			ID          uint   `json:"id"`
+			Name        string `json:"name"`
+			Description string `json:"description"`
+			Role        string `json:"role"`
+			CreatedAt   string `json:"created_at"`
+		}{
+			ID:          membership.Organization.ID,
+			Name:        membership.Organization.Name,
+			Description: membership.Organization.Description,
+			Role:        membership.Role,
+			CreatedAt:   membership.Organization.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return response, nil
+}
+
+type CreateOrganizationRequest struct {
+	Name        string `json:"name" binding:"required"`
+	Description string `json:"description"`
+}
+
+type CreateOrganizationResponse struct {
+	ID      uint   `json:"id"`
+	Message string `json:"message"`
+}
+
+func (s *OrganizationService) CreateOrganization(userId uint, req CreateOrganizationRequest) (CreateOrganizationResponse, errors.CustomError) {
+	if !validator.IsValidApplicationName(req.Name) {
+		return CreateOrganizationResponse{}, errors.BadRequest("invalid organization name")
+	}
+
+	var organization models.Organization
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var existing models.Organization
+		if err := tx.Unscoped().Where("name = ?", req.Name).First(&existing).Error; err == nil {
+			return errors.Conflict("organization name already exists")
+		}
+
+		organization = models.Organization{
+			Name:        req.Name,
+			Description: req.Description,
+		}
+		if err := tx.Create(&organization).Error; err != nil {
+			return errors.Internal("failed to create organization")
+		}
+
+		member := models.OrganizationMember{
+			OrganizationID: organization.ID,
+			UserID:         userId,
+			Role:           models.OrganizationRoleOwner,
+		}
+		if err := tx.Create(&member).Error; err != nil {
+			return errors.Internal("failed to add organization owner")
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return CreateOrganizationResponse{}, customErr
+		}
+		return CreateOrganizationResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	return CreateOrganizationResponse{
+		ID:      organization.ID,
+		Message: "Organization created successfully",
+	}, nil
+}
+
+type GetOrganizationResponse struct {
+	ID          uint   `json:"id"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	Members     []struct {
+		UserID   uint   `json:"user_id"`
+		Username string `json:"username"`
+		Role     string `json:"role"`
+	} `json:"members"`
+	Applications []struct {
+		ID     uint   `json:"id"`
+		Name   string `json:"name"`
+		Status string `json:"status"`
+	} `json:"applications"`
+	CreatedAt string `json:"created_at"`
+}
+
+func (s *OrganizationService) GetOrganization(userId uint, orgId uint) (GetOrganizationResponse, errors.CustomError) {
+	if _, err := authorizeOrganization(s.db, userId, orgId, models.OrganizationRoleMember); err != nil {
+		return GetOrganizationResponse{}, err
+	}
+
+	var organization models.Organization
+	if err := s.db.Preload("Members.User").Preload("Applications").First(&organization, orgId).Error; err != nil {
+		return GetOrganizationResponse{}, errors.NotFound("organization not found")
+	}
+
+	response := GetOrganizationResponse{
+		ID:          organization.ID,
+		Name:        organization.Name,
+		Description: organization.Description,
+		CreatedAt:   organization.CreatedAt.Format("2006-01-02 15:04:05"),
+	}
+	for _, member := range organization.Members {
+		response.Members = append(response.Members, struct {
+			UserID   uint   `json:"user_id"`
+			Username string `json:"username"`
+			Role     string `json:"role"`
+		}{
+			UserID:   member.UserID,
+			Username: member.User.Username,
+			Role:     member.Role,
+		})
+	}
+	for _, app := range organization.Applications {
+		response.Applications = append(response.Applications, struct {
+			ID     uint   `json:"id"`
+			Name   string `json:"name"`
+			Status string `json:"status"`
+		}{
+			ID:     app.ID,
+			Name:   app.Name,
+			Status: app.Status,
+		})
+	}
+
+	return response, nil
+}
+
+type DeleteOrganizationResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *OrganizationService) DeleteOrganization(userId uint, orgId uint) (DeleteOrganizationResponse, errors.CustomError) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := authorizeOrganization(tx, userId, orgId, models.OrganizationRoleOwner); err != nil {
+			return err
+		}
+
+		var count int64
+		if err := tx.Model(&models.Application{}).Where("organization_id = ?", orgId).Count(&count).Error; err != nil {
+			return errors.Internal("failed to check organization applications")
+		}
+		if count > 0 {
+			return errors.BadRequest("organization still owns applications")
+		}
+
+		if err := tx.Where("organization_id = ?", orgId).Delete(&models.OrganizationInvitation{}).Error; err != nil {
+			return errors.Internal("failed to delete invitations")
+		}
+
+		if err := tx.Where("organization_id = ?", orgId).Delete(&models.OrganizationMember{}).Error; err != nil {
+			return errors.Internal("failed to delete members")
+		}
+
+		if err := tx.Delete(&models.Organization{}, orgId).Error; err != nil {
+			return errors.Internal("failed to delete organization")
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return DeleteOrganizationResponse{}, customErr
+		}
+		return DeleteOrganizationResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	return DeleteOrganizationResponse{
+		Message: "Organization deleted successfully",
+	}, nil
+}
+
+type UpdateOrganizationMemberRequest struct {
+	Role string `json:"role" binding:"required"`
+}
+
+type UpdateOrganizationMemberResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *OrganizationService) UpdateOrganizationMember(userId uint, orgId uint, memberUserId uint, req UpdateOrganizationMemberRequest) (UpdateOrganizationMemberResponse, errors.CustomError) {
+	if !models.IsValidOrganizationRole(req.Role) {
+		return UpdateOrganizationMemberResponse{}, errors.BadRequest("invalid role")
+	}
+
+	var organization models.Organization
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if _, err := authorizeOrganization(tx, userId, orgId, models.OrganizationRoleOwner); err != nil {
+			return err
+		}
+
+		if err := tx.First(&organization, orgId).Error; err != nil {
+			return errors.NotFound("organization not found")
+		}
+
+		var member models.OrganizationMember
+		if err := tx.Where("organization_id = ? AND user_id = ?", orgId, memberUserId).First(&member).Error; err != nil {
+			return errors.NotFound("member not found")
+		}
+
+		if member.Role == models.OrganizationRoleOwner && req.Role != models.OrganizationRoleOwner {
+			if err := ensureAnotherOwner(tx, orgId, memberUserId); err != nil {
+				return err
+			}
+		}
+
+		member.Role = req.Role
+		if err := tx.Save(&member).Error; err != nil {
+			return errors.Internal("failed to update member")
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return UpdateOrganizationMemberResponse{}, customErr
+		}
+		return UpdateOrganizationMemberResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	s.notificationService.CreateNotification(memberUserId, fmt.Sprintf("Your role in organization %s is now %s", organization.Name, req.Role))
+
+	return UpdateOrganizationMemberResponse{
+		Message: "Member updated successfully",
+	}, nil
+}
+
+type RemoveOrganizationMemberResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *OrganizationService) RemoveOrganizationMember(userId uint, orgId uint, memberUserId uint) (RemoveOrganizationMemberResponse, errors.CustomError) {
+	var organization models.Organization
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		// Members may always leave; removing someone else takes a maintainer, and
+		// only owners may remove owners.
+		actor, err := authorizeOrganization(tx, userId, orgId, models.OrganizationRoleMember)
+		if err != nil {
+			return err
+		}
+
+		if err := tx.First(&organization, orgId).Error; err != nil {
+			return errors.NotFound("organization not found")
+		}
+
+		var member models.OrganizationMember
+		if err := tx.Where("organization_id = ? AND user_id = ?", orgId, memberUserId).First(&member).Error; err != nil {
+			return errors.NotFound("member not found")
+		}
+
+		if userId != memberUserId && organizationRoleRanks[actor.Role] < organizationRoleRanks[models.OrganizationRoleMaintainer] {
+			return errors.Forbidden("permission denied")
+		}
+		if userId != memberUserId && member.Role == models.OrganizationRoleOwner && actor.Role != models.OrganizationRoleOwner {
+			return errors.Forbidden("permission denied")
+		}
+
+		if member.Role == models.OrganizationRoleOwner {
+			if err := ensureAnotherOwner(tx, orgId, memberUserId); err != nil {
+				return err
+			}
+		}
+
+		if err := tx.Unscoped().Delete(&member).Error; err != nil {
+			return errors.Internal("failed to remove member")
+		}
+
+		return cancelMemberApplicationTransfers(tx, orgId, memberUserId)
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return RemoveOrganizationMemberResponse{}, customErr
+		}
+		return RemoveOrganizationMemberResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	if userId != memberUserId {
+		s.notificationService.CreateNotification(memberUserId, fmt.Sprintf("You have been removed from organization %s", organization.Name))
+	}
+
+	return RemoveOrganizationMemberResponse{
+		Message: "Member removed successfully",
+	}, nil
+}
+
+type GetOrganizationInvitationsResponse struct {
+	Invitations []struct {
+		ID        uint   `json:"id"`
+		Email     string `json:"email"`
+		Role      string `json:"role"`
+		InvitedBy string `json:"invited_by"`
+		ExpiresAt string `json:"expires_at"`
+	} `json:"invitations"`
+}
+
+func (s *OrganizationService) GetOrganizationInvitations(userId uint, orgId uint) (GetOrganizationInvitationsResponse, errors.CustomError) {
+	if _, err := authorizeOrganization(s.db, userId, orgId, models.OrganizationRoleMaintainer); err != nil {
+		return GetOrganizationInvitationsResponse{}, err
+	}
+
+	var invitations []models.OrganizationInvitation
+	err := s.db.Preload("InvitedBy").
+		Where("organization_id = ? AND accepted_at IS NULL AND expires_at > ?", orgId, time.Now()).
+		Find(&invitations).Error
+	if err != nil {
+		return GetOrganizationInvitationsResponse{}, errors.Internal("failed to retrieve invitations")
+	}
+
+	var response GetOrganizationInvitationsResponse
+	for _, invitation := range invitations {
+		response.Invitations = append(response.Invitations, struct {
+			ID        uint   `json:"id"`
+			Email     string `json:"email"`
+			Role      string `json:"role"`
+			InvitedBy string `json:"invited_by"`
+			ExpiresAt string `json:"expires_at"`
+		}{
+			ID:        invitation.ID,
+			Email:     invitation.Email,
+			Role:      invitation.Role,
+			InvitedBy: invitation.InvitedBy.Username,
+			ExpiresAt: invitation.ExpiresAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return response, nil
+}
+
+type InviteOrganizationMemberRequest struct {
+	Email string `json:"email" binding:"required"`
+	Role  string `json:"role" binding:"required"`
+}
+
+type InviteOrganizationMemberResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *OrganizationService) InviteOrganizationMember(userId uint, orgId uint, req InviteOrganizationMemberRequest) (InviteOrganizationMemberResponse, errors.CustomError) {
+	address := strings.ToLower(strings.TrimSpace(req.Email))
+	if !validator.IsValidEmail(address) {
+		return InviteOrganizationMemberResponse{}, errors.BadRequest("invalid email")
+	}
+
+	if !models.IsValidOrganizationRole(req.Role) {
+		return InviteOrganizationMemberResponse{}, errors.BadRequest("invalid role")
+	}
+
+	var organization models.Organization
+	var inviter models.User
+	var invitee *models.User
+	var invitationToken string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		actor, err := authorizeOrganization(tx, userId, orgId, models.OrganizationRoleMaintainer)
+		if err != nil {
+			return err
+		}
+
+		if organizationRoleRanks[req.Role] > organizationRoleRanks[actor.Role] {
+			return errors.Forbidden("cannot invite with a role higher than your own")
+		}
+
+		if err := tx.First(&organization, orgId).Error; err != nil {
+			return errors.NotFound("organization not found")
+		}
+
+		if err := tx.First(&inviter, userId).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		var existingUser models.User
+		if err := tx.Where("email = ?", address).First(&existingUser).Error; err == nil {
+			var count int64
+			if err := tx.Model(&models.OrganizationMember{}).Where("organization_id = ? AND user_id = ?", orgId, existingUser.ID).Count(&count).Error; err != nil {
+				return errors.Internal("failed to check membership")
+			}
+			if count > 0 {
+				return errors.Conflict("user is already a member")
+			}
+			invitee = &existingUser
+		}
+
+		if err := tx.Where("organization_id = ? AND email = ? AND accepted_at IS NULL", orgId, address).Delete(&models.OrganizationInvitation{}).Error; err != nil {
+			return errors.Internal("failed to replace previous invitation")
+		}
+
+		generated, genErr := token.Generate(32)
+		if genErr != nil {
+			return errors.Internal("failed to generate invitation token")
+		}
+		invitationToken = generated
+
+		invitation := models.OrganizationInvitation{
+			OrganizationID: orgId,
+			Email:          address,
+			Role:           req.Role,
+			TokenHash:      token.Hash(invitationToken),
+			InvitedByID:    userId,
+			ExpiresAt:      time.Now().Add(organizationInvitationTTL),
+		}
+		if err := tx.Create(&invitation).Error; err != nil {
+			return errors.Internal("failed to create invitation")
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return InviteOrganizationMemberResponse{}, customErr
+		}
+		return InviteOrganizationMemberResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	link := fmt.Sprintf("%s/organizations/invitations/accept?token=%s", dashboardURL(), invitationToken)
+	if err := email.SendOrganizationInvitationEmail(address, organization.Name, inviter.Username, link, int(organizationInvitationTTL.Hours()/24)); err != nil {
+		// The invitation is already committed, so a delivery failure must not
+		// fail the request.
+		log.Printf("Failed to send organization invitation email to %s: %v\n", address, err)
+	}
+
+	if invitee != nil {
+		s.notificationService.CreateNotification(invitee.ID, fmt.Sprintf("%s invited you to join organization %s", inviter.Username, organization.Name))
+	}
+
+	return InviteOrganizationMemberResponse{
+		Message: "Invitation sent successfully",
+	}, nil
+}
+
+type RevokeOrganizationInvitationResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *OrganizationService) RevokeOrganizationInvitation(userId uint, orgId uint, invitationId uint) (RevokeOrganizationInvitationResponse, errors.CustomError) {
+	if _, err := authorizeOrganization(s.db, userId, orgId, models.OrganizationRoleMaintainer); err != nil {
+		return RevokeOrganizationInvitationResponse{}, err
+	}
+
+	result := s.db.Where("id = ? AND organization_id = ? AND accepted_at IS NULL", invitationId, orgId).Delete(&models.OrganizationInvitation{})
+	if result.Error != nil {
+		return RevokeOrganizationInvitationResponse{}, errors.Internal("failed to revoke invitation")
+	}
+	if result.RowsAffected == 0 {
+		return RevokeOrganizationInvitationResponse{}, errors.NotFound("invitation not found")
+	}
+
+	return RevokeOrganizationInvitationResponse{
+		Message: "Invitation revoked successfully",
+	}, nil
+}
+
+type AcceptOrganizationInvitationRequest struct {
+	Token string `json:"token" binding:"required"`
+}
+
+type AcceptOrganizationInvitationResponse struct {
+	OrganizationID uint   `json:"organization_id"`
+	Message        string `json:"message"`
+}
+
+func (s *OrganizationService) AcceptOrganizationInvitation(userId uint, req AcceptOrganizationInvitationRequest) (AcceptOrganizationInvitationResponse, errors.CustomError) {
+	var invitation models.OrganizationInvitation
+	var user models.User
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Preload("Organization").Where("token_hash = ?", token.Hash(req.Token)).First(&invitation).Error; err != nil {
+			return errors.BadRequest("invalid or expired invitation")
+		}
+
+		now := time.Now()
+		if invitation.AcceptedAt != nil || now.After(invitation.ExpiresAt) {
+			return errors.BadRequest("invalid or expired invitation")
+		}
+
+		if err := tx.First(&user, userId).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		// The invitation was addressed to an email, so only the account that has
+		// proven ownership of that address may redeem it.
+		if !user.IsEmailVerified() || !strings.EqualFold(user.Email, invitation.Email) {
+			return errors.Forbidden("invitation was sent to a different email address")
+		}
+
+		var count int64
+		if err := tx.Model(&models.OrganizationMember{}).Where("organization_id = ? AND user_id = ?", invitation.OrganizationID, userId).Count(&count).Error; err != nil {
+			return errors.Internal("failed to check membership")
+		}
+		if count > 0 {
+			return errors.Conflict("already a member of this organization")
+		}
+
+		member := models.OrganizationMember{
+			OrganizationID: invitation.OrganizationID,
+			UserID:         userId,
+			Role:           invitation.Role,
+		}
+		if err := tx.Create(&member).Error; err != nil {
+			return errors.Internal("failed to join organization")
+		}
+
+		invitation.AcceptedAt = &now
+		if err := tx.Save(&invitation).Error; err != nil {
+			return errors.Internal("failed to consume invitation")
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return AcceptOrganizationInvitationResponse{}, customErr
+		}
+		return AcceptOrganizationInvitationResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	s.notificationService.CreateNotification(invitation.InvitedByID, fmt.Sprintf("%s joined organization %s", user.Username, invitation.Organization.Name))
+
+	return AcceptOrganizationInvitationResponse{
+		OrganizationID: invitation.OrganizationID,
+		Message:        "Joined organization successfully",
+	}, nil
+}
+
+func ensureAnotherOwner(tx *gorm.DB, orgId uint, excludeUserId uint) errors.CustomError {
+	// Lock every owner row so concurrent demotions and removals cannot both
+	// pass the last-owner check.
+	var owners []models.OrganizationMember
+	if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("organization_id = ? AND role = ?", orgId, models.OrganizationRoleOwner).Find(&owners).Error; err != nil {
+		return errors.Internal("failed to check organization owners")
+	}
+
+	for _, owner := range owners {
+		if owner.UserID != excludeUserId {
+			return nil
+		}
+	}
+
+	return errors.BadRequest("organization must keep at least one owner")
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/errors"
+	"github.com/injunweb/backend-server/pkg/token"
+)
+
+const (
+	PersonalAccessTokenPrefix = "ijw_pat_"
+
+	defaultTokenExpiryDays = 90
+	maxTokenExpiryDays     = 365
+	lastUsedUpdateInterval = time.Minute
+)
+
+type GetPersonalAccessTokensResponse struct {
+	Tokens []struct {
+		ID          uint     `json:"id"`
+		Name        string   `json:"name"`
+		TokenPrefix string   `json:"token_prefix"`
+		Scopes      []string `json:"scopes"`
+		ExpiresAt   string   `json:"expires_at"`
+		LastUsedAt  string   `json:"last_used_at"`
+		CreatedAt   string   `json:"created_at"`
+	} `json:"tokens"`
+}
+
+func (s *AuthService) GetPersonalAccessTokens(userId uint) (GetPersonalAccessTokensResponse, errors.CustomError) {
+	var tokens []models.PersonalAccessToken
+	if err := s.db.Where("user_id = ?", userId).Order("created_at DESC").Find(&tokens).Error; err != nil {
+		return GetPersonalAccessTokensResponse{}, errors.Internal("failed to retrieve tokens")
+	}
+
+	var response GetPersonalAccessTokensResponse
+	for _, t := range tokens {
+		response.Tokens = append(response.Tokens, struct {
+			ID          uint     `json:"id"`
+			Name        string   `json:"name"`
+			TokenPrefix string   `json:"token_prefix"`
+			Scopes      []string `json:"scopes"`
+			ExpiresAt   string   `json:"expires_at"`
+			LastUsedAt  string   `json:"last_used_at"`
+			CreatedAt   string   `json:"created_at"`
+		}{
+			ID:          t.ID,
+			Name:        t.Name,
+			TokenPrefix: t.TokenPrefix,
+			Scopes:      t.ScopeList(),
+			ExpiresAt:   formatOptionalTime(t.ExpiresAt),
+			LastUsedAt:  formatOptionalTime(t.LastUsedAt),
+			CreatedAt:   t.CreatedAt.Format("2006-01-02 15:04:05"),
+		})
+	}
+
+	return response, nil
+}
+
+type CreatePersonalAccessTokenRequest struct {
+	Name          string   `json:"name" binding:"required"`
+	Scopes        []string `json:"scopes" binding:"required"`
+	ExpiresInDays int      `json:"expires_in_days"`
+}
+
+type CreatePersonalAccessTokenResponse struct {
+	ID        uint     `json:"id"`
+	Token     string   `json:"token"`
+	Scopes    []string `json:"scopes"`
+	ExpiresAt string   `json:"expires_at"`
+	Message   string   `json:"message"`
+}
+
+func (s *AuthService) CreatePersonalAccessToken(userId uint, req CreatePersonalAccessTokenRequest) (CreatePersonalAccessTokenResponse, errors.CustomError) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" || len(name) > 255 {
+		return CreatePersonalAccessTokenResponse{}, errors.BadRequest("invalid token name")
+	}
+
+	if len(req.Scopes) == 0 {
+		return CreatePersonalAccessTokenResponse{}, errors.BadRequest("at least one scope is required")
+	}
+
+	scopes := make([]string, 0, len(req.Scopes))
+	seen := make(map[string]bool)
+	for _, scope := range req.Scopes {
+		if !models.IsValidScope(scope) {
+			return CreatePersonalAccessTokenResponse{}, errors.BadRequest(fmt.Sprintf("invalid scope: %s", scope))
+		}
+		if !seen[scope] {
+			seen[scope] = true
+			scopes = append(scopes, scope)
+		}
+	}
+
+	expiresInDays := req.ExpiresInDays
+	if expiresInDays == 0 {
+		expiresInDays = defaultTokenExpiryDays
+	}
+	if expiresInDays < 0 || expiresInDays > maxTokenExpiryDays {
+		return CreatePersonalAccessTokenResponse{}, errors.BadRequest(fmt.Sprintf("expiry must be between 1 and %d days", maxTokenExpiryDays))
+	}
+
+	secret, err := token.Generate(32)
+	if err != nil {
+		return CreatePersonalAccessTokenResponse{}, errors.Internal("failed to generate token")
+	}
+
+	rawToken := PersonalAccessTokenPrefix + secret
+	expiresAt := time.Now().AddDate(0, 0, expiresInDays)
+	record := models.PersonalAccessToken{
+		UserID:      userId,
+		Name:        name,
+		TokenHash:   token.Hash(rawToken),
+		TokenPrefix: rawToken[:len(PersonalAccessTokenPrefix)+6],
+		Scopes:      strings.Join(scopes, ","),
+		ExpiresAt:   &expiresAt,
+	}
+
+	if err := s.db.Create(&record).Error; err != nil {
+		return CreatePersonalAccessTokenResponse{}, errors.Internal("failed to create token")
+	}
+
+	return CreatePersonalAccessTokenResponse{
+		ID:        record.ID,
+		Token:     rawToken,
+		Scopes:    scopes,
+		ExpiresAt: expiresAt.Format("2006-01-02 15:04:05"),
+		Message:   "Token created successfully, copy it now as it will not be shown again",
+	}, nil
+}
+
+type DeletePersonalAccessTokenResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *AuthService) DeletePersonalAccessToken(userId uint, tokenId uint) (DeletePersonalAccessTokenResponse, errors.CustomError) {
+	result := s.db.Where("id = ? AND user_id = ?", tokenId, userId).Delete(&models.PersonalAccessToken{})
+	if result.Error != nil {
+		return DeletePersonalAccessTokenResponse{}, errors.Internal("failed to delete token")
+	}
+	if result.RowsAffected == 0 {
+		return DeletePersonalAccessTokenResponse{}, errors.NotFound("token not found")
+	}
+
+	return DeletePersonalAccessTokenResponse{
+		Message: "Token deleted successfully",
+	}, nil
+}
+
+func (s *AuthService) ValidatePersonalAccessToken(rawToken string) (AccessTokenClaims, errors.CustomError) {
+	var record models.PersonalAccessToken
+	if err := s.db.Where("token_hash = ?", token.Hash(rawToken)).First(&record).Error; err != nil {
+		return AccessTokenClaims{}, errors.Unauthorized("invalid token")
+	}
+
+	now := time.Now()
+	if record.ExpiresAt != nil && now.After(*record.ExpiresAt) {
+		return AccessTokenClaims{}, errors.Unauthorized("token expired")
+	}
+
+	if record.LastUsedAt == nil || now.Sub(*record.LastUsedAt) > lastUsedUpdateInterval {
+		s.db.Model(&record).UpdateColumn("last_used_at", now)
+	}
+
+	return AccessTokenClaims{
+		UserID: record.UserID,
+		Role:   models.RoleUser,
+		Scopes: record.ScopeList(),
+	}, nil
+}
+
+func formatOptionalTime(t *time.Time) string {
+	if t == nil {
+		return ""
+	}
+
+	return t.Format("2006-01-02 15:04:05")
+}
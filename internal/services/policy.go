@@ -0,0 +1,108 @@
+package services
+
+import (
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/errors"
+
+	"gorm.io/gorm"
+)
+
+type applicationAccess int
+
+const (
+	applicationAccessRead applicationAccess = iota + 1
+	applicationAccessWrite
+	applicationAccessAdmin
+)
+
+var organizationRoleRanks = map[string]int{
+	models.OrganizationRoleMember:     1,
+	models.OrganizationRoleMaintainer: 2,
+	models.OrganizationRoleOwner:      3,
+}
+
+var organizationRoleAccess = map[string]applicationAccess{
+	models.OrganizationRoleMember:     applicationAccessRead,
+	models.OrganizationRoleMaintainer: applicationAccessWrite,
+	models.OrganizationRoleOwner:      applicationAccessAdmin,
+}
+
+var collaboratorPermissionAccess = map[string]applicationAccess{
+	models.CollaboratorPermissionRead:  applicationAccessRead,
+	models.CollaboratorPermissionWrite: applicationAccessWrite,
+}
+
+func authorize(tx *gorm.DB, userId uint, permission string) errors.CustomError {
+	var user models.User
+	if err := tx.Select("id", "role").First(&user, userId).Error; err != nil {
+		return errors.Unauthorized("user not found")
+	}
+
+	if !user.HasPermission(permission) {
+		return errors.Forbidden("permission denied")
+	}
+
+	return nil
+}
+
+// ownsApplication reports whether the user owns the application. Ownership of
+// an organization application only counts while the owner is still a member.
+func ownsApplication(tx *gorm.DB, userId uint, application models.Application) bool {
+	if application.OwnerID != userId {
+		return false
+	}
+	if application.OrganizationID == nil {
+		return true
+	}
+
+	var count int64
+	tx.Model(&models.OrganizationMember{}).Where("organization_id = ? AND user_id = ?", *application.OrganizationID, userId).Count(&count)
+	return count > 0
+}
+
+func authorizeApplication(tx *gorm.DB, userId uint, application models.Application, access applicationAccess) errors.CustomError {
+	if ownsApplication(tx, userId, application) {
+		return nil
+	}
+
+	if application.OrganizationID != nil {
+		var member models.OrganizationMember
+		err := tx.Where("organization_id = ? AND user_id = ?", *application.OrganizationID, userId).First(&member).Error
+		if err == nil && organizationRoleAccess[member.Role] >= access {
+			return nil
+		}
+	}
+
+	var collaborator models.ApplicationCollaborator
+	err := tx.Where("application_id = ? AND user_id = ?", application.ID, userId).First(&collaborator).Error
+	if err == nil && collaboratorPermissionAccess[collaborator.Permission] >= access {
+		return nil
+	}
+
+	// Staff act on other users' applications only through the admin API, which
+	// requires a two-factor verified session.
+	return errors.Forbidden("permission denied")
+}
+
+// authorizeOrganization requires the user to hold at least minRole in the
+// organization and returns their membership.
+func authorizeOrganization(tx *gorm.DB, userId uint, organizationId uint, minRole string) (models.OrganizationMember, errors.CustomError) {
+	var member models.OrganizationMember
+	if err := tx.Where("organization_id = ? AND user_id = ?", organizationId, userId).First(&member).Error; err != nil {
+		return models.OrganizationMember{}, errors.NotFound("organization not found")
+	}
+
+	if organizationRoleRanks[member.Role] < organizationRoleRanks[minRole] {
+		return models.OrganizationMember{}, errors.Forbidden("permission denied")
+	}
+
+	return member, nil
+}
+
+// accessibleApplications scopes a query to applications the user owns, that
+// belong to one of their organizations, or that were shared with them.
+func accessibleApplications(tx *gorm.DB, userId uint) *gorm.DB {
+	organizationIds := tx.Model(&models.OrganizationMember{}).Select("organization_id").Where("user_id = ?", userId)
+	sharedIds := tx.Model(&models.ApplicationCollaborator{}).Select("application_id").Where("user_id = ?", userId)
+	return tx.Where("(owner_id = ? AND organization_id IS NULL) OR organization_id IN (?) OR id IN (?)", userId, organizationIds, sharedIds)
+}
@@ -0,0 +1,548 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/database"
+	"github.com/injunweb/backend-server/pkg/email"
+	"github.com/injunweb/backend-server/pkg/errors"
+	"github.com/injunweb/backend-server/pkg/github"
+	"github.com/injunweb/backend-server/pkg/harbor"
+	"github.com/injunweb/backend-server/pkg/kubernetes"
+	"github.com/injunweb/backend-server/pkg/vault"
+
+	"gorm.io/gorm"
+)
+
+// ProvisioningService runs the external side effects of approving an
+// application as an ordered list of steps. Each step is idempotent and has a
+// compensating action, and its state is stored per application so an
+// interrupted run can be resumed or undone.
+type ProvisioningService struct {
+	db                  *gorm.DB
+	notificationService *NotificationService
+	jobService          *JobService
+}
+
+func NewProvisioningService(db *gorm.DB, notificationService *NotificationService, jobService *JobService) *ProvisioningService {
+	s := &ProvisioningService{db: db, notificationService: notificationService, jobService: jobService}
+
+	jobService.Register(jobTypeProvisionApplication, JobHandler{Run: s.runProvisionJob, OnFailure: s.failProvisionJob})
+	jobService.Register(jobTypeTeardownApplication, JobHandler{Run: s.runTeardownJob, OnFailure: s.failApplicationJob})
+	jobService.Register(jobTypeDeleteApplication, JobHandler{Run: s.runDeleteJob, OnFailure: s.failApplicationJob})
+	jobService.Register(jobTypeRotateCredentials, JobHandler{Run: s.runRotateCredentialsJob, OnFailure: s.failRotateCredentialsJob})
+	jobService.Register(jobTypeApplyApplicationStatus, JobHandler{Run: s.runApplyStatusJob, OnFailure: s.failApplyStatusJob})
+
+	return s
+}
+
+type provisioningRun struct {
+	application models.Application
+	owner       models.User
+	dbPassword  string
+}
+
+type provisioningStep struct {
+	name       string
+	apply      func(run *provisioningRun) error
+	compensate func(application models.Application) error
+}
+
+var provisioningSteps = []provisioningStep{
+	{
+		name: models.ProvisioningStepVault,
+		apply: func(run *provisioningRun) error {
+			return vault.InitSecret(run.application.Name, map[string]interface{}{"INIT": "INIT"})
+		},
+		compensate: func(application models.Application) error {
+			return vault.DeleteSecret(application.Name)
+		},
+	},
+	{
+		name: models.ProvisioningStepDatabase,
+		apply: func(run *provisioningRun) error {
+			password, err := database.CreateDatabaseAndUser(run.application.Name)
+			if err != nil {
+				return err
+			}
+			run.dbPassword = password
+			return nil
+		},
+		compensate: func(application models.Application) error {
+			return database.DeleteDatabaseAndUser(application.Name)
+		},
+	},
+	{
+		name: models.ProvisioningStepQuota,
+		apply: func(run *provisioningRun) error {
+			return applyApplicationQuota(run.application)
+		},
+		// The quota objects go away with the namespace.
+		compensate: func(application models.Application) error {
+			return nil
+		},
+	},
+	{
+		name: models.ProvisioningStepGitOps,
+		apply: func(run *provisioningRun) error {
+			return github.TriggerWriteValuesWorkflow(run.application)
+		},
+		// Removing the pipeline stops new deployments; the namespace and
+		// images it produced are cleaned up here as well.
+		compensate: func(application models.Application) error {
+			if kubernetes.NamespaceExists(application.Name) {
+				if err := kubernetes.DeleteNamespace(application.Name); err != nil {
+					return fmt.Errorf("failed to delete namespace: %v", err)
+				}
+			}
+
+			exists, err := harbor.RepositoryExists(application.Name)
+			if err != nil {
+				return fmt.Errorf("failed to check Harbor repository: %v", err)
+			}
+			if exists {
+				if err := harbor.DeleteRepository(application.Name); err != nil {
+					return fmt.Errorf("failed to delete Harbor repository: %v", err)
+				}
+			}
+
+			return github.TriggerRemovePipelineWorkflow(application)
+		},
+	},
+	{
+		name: models.ProvisioningStepCredentials,
+		apply: func(run *provisioningRun) error {
+			// A resumed run no longer knows the password generated by the
+			// database step, so issue a fresh one.
+			if run.dbPassword == "" {
+				password, err := database.RotateUserPassword(run.application.Name)
+				if err != nil {
+					return err
+				}
+				run.dbPassword = password
+			}
+			return email.SendApprovalEmail(run.owner.Email, run.application.Name, run.dbPassword)
+		},
+		compensate: func(application models.Application) error {
+			return nil
+		},
+	},
+}
+
+// Provision runs every step that has not completed yet. On failure the
+// completed steps are kept so the next run resumes from the failed step;
+// Teardown undoes them.
+func (s *ProvisioningService) Provision(application models.Application, owner models.User) errors.CustomError {
+	states, err := s.loadSteps(application.ID)
+	if err != nil {
+		return err
+	}
+
+	run := &provisioningRun{application: application, owner: owner}
+	for _, step := range provisioningSteps {
+		state := states[step.name]
+		if state.Status == models.ProvisioningStepStatusCompleted {
+			continue
+		}
+
+		state.Attempts++
+		if applyErr := step.apply(run); applyErr != nil {
+			state.Status = models.ProvisioningStepStatusFailed
+			state.LastError = truncate(applyErr.Error(), 1024)
+			if err := s.db.Save(&state).Error; err != nil {
+				return errors.Internal("failed to record provisioning step")
+			}
+			return errors.BadGateway(fmt.Sprintf("provisioning step %s failed: %v", step.name, applyErr))
+		}
+
+		now := time.Now()
+		state.Status = models.ProvisioningStepStatusCompleted
+		state.LastError = ""
+		state.CompletedAt = &now
+		if err := s.db.Save(&state).Error; err != nil {
+			return errors.Internal("failed to record provisioning step")
+		}
+	}
+
+	return nil
+}
+
+// Teardown compensates steps in reverse order. Steps without a record are
+// compensated too, since applications approved before steps were tracked
+// have no state, and every compensating action is safe to repeat.
+func (s *ProvisioningService) Teardown(application models.Application) errors.CustomError {
+	states, err := s.loadSteps(application.ID)
+	if err != nil {
+		return err
+	}
+
+	for i := len(provisioningSteps) - 1; i >= 0; i-- {
+		step := provisioningSteps[i]
+		state := states[step.name]
+		if state.Status == models.ProvisioningStepStatusCompensated {
+			continue
+		}
+
+		if compensateErr := step.compensate(application); compensateErr != nil {
+			state.LastError = truncate(compensateErr.Error(), 1024)
+			if err := s.db.Save(&state).Error; err != nil {
+				return errors.Internal("failed to record provisioning step")
+			}
+			return errors.BadGateway(fmt.Sprintf("compensating step %s failed: %v", step.name, compensateErr))
+		}
+
+		state.Status = models.ProvisioningStepStatusCompensated
+		state.LastError = ""
+		state.CompletedAt = nil
+		if err := s.db.Save(&state).Error; err != nil {
+			return errors.Internal("failed to record provisioning step")
+		}
+	}
+
+	return nil
+}
+
+type ProvisioningStepResponse struct {
+	Step      string `json:"step"`
+	Status    string `json:"status"`
+	Attempts  int    `json:"attempts"`
+	LastError string `json:"last_error"`
+	UpdatedAt string `json:"updated_at"`
+}
+
+func (s *ProvisioningService) GetSteps(appId uint) ([]ProvisioningStepResponse, errors.CustomError) {
+	states, err := s.loadSteps(appId)
+	if err != nil {
+		return nil, err
+	}
+
+	var response []ProvisioningStepResponse
+	for _, step := range provisioningSteps {
+		state := states[step.name]
+		updatedAt := ""
+		if state.ID != 0 {
+			updatedAt = state.UpdatedAt.Format("2006-01-02 15:04:05")
+		}
+		response = append(response, ProvisioningStepResponse{
+			Step:      state.Step,
+			Status:    state.Status,
+			Attempts:  state.Attempts,
+			LastError: state.LastError,
+			UpdatedAt: updatedAt,
+		})
+	}
+
+	return response, nil
+}
+
+// loadSteps returns the stored state of every step, filling in unsaved
+// Pending entries for steps that have never run.
+func (s *ProvisioningService) loadSteps(appId uint) (map[string]models.ProvisioningStep, errors.CustomError) {
+	var stored []models.ProvisioningStep
+	if err := s.db.Where("application_id = ?", appId).Find(&stored).Error; err != nil {
+		return nil, errors.Internal("failed to retrieve provisioning steps")
+	}
+
+	states := make(map[string]models.ProvisioningStep)
+	for _, step := range provisioningSteps {
+		states[step.name] = models.ProvisioningStep{
+			ApplicationID: appId,
+			Step:          step.name,
+			Status:        models.ProvisioningStepStatusPending,
+		}
+	}
+	for _, state := range stored {
+		states[state.Step] = state
+	}
+
+	return states, nil
+}
+
+func truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return s[:max]
+}
+
+const (
+	jobTypeProvisionApplication   = "application.provision"
+	jobTypeTeardownApplication    = "application.teardown"
+	jobTypeDeleteApplication      = "application.delete"
+	jobTypeRotateCredentials      = "application.rotate_credentials"
+	jobTypeApplyApplicationStatus = "application.apply_status"
+)
+
+// databasePasswordSecretKey is the key under which the application's database
+// password is kept in its Vault secret.
+const databasePasswordSecretKey = "DB_PASSWORD"
+
+type applicationJobPayload struct {
+	ApplicationID uint `json:"application_id"`
+	HadResources  bool `json:"had_resources"`
+}
+
+func applicationJobSubject(appId uint) string {
+	return fmt.Sprintf("%s%d", applicationJobSubjectPrefix, appId)
+}
+
+// EnqueueProvision queues provisioning of an application that has already
+// been moved to Provisioning within tx.
+func (s *ProvisioningService) EnqueueProvision(tx *gorm.DB, application models.Application, actorId uint) (models.Job, errors.CustomError) {
+	return s.jobService.Enqueue(tx, jobTypeProvisionApplication, applicationJobSubject(application.ID), applicationJobPayload{ApplicationID: application.ID}, &actorId)
+}
+
+// EnqueueTeardown queues removal of the resources of an application that has
+// already been moved to Deprovisioning within tx, after which it returns to
+// Pending.
+func (s *ProvisioningService) EnqueueTeardown(tx *gorm.DB, application models.Application, actorId uint) (models.Job, errors.CustomError) {
+	return s.jobService.Enqueue(tx, jobTypeTeardownApplication, applicationJobSubject(application.ID), applicationJobPayload{ApplicationID: application.ID}, &actorId)
+}
+
+// EnqueueDelete queues deletion of an application that has already been moved
+// to Deleting within tx.
+func (s *ProvisioningService) EnqueueDelete(tx *gorm.DB, application models.Application, hadResources bool, actorId uint) (models.Job, errors.CustomError) {
+	return s.jobService.Enqueue(tx, jobTypeDeleteApplication, applicationJobSubject(application.ID), applicationJobPayload{ApplicationID: application.ID, HadResources: hadResources}, &actorId)
+}
+
+// EnqueueCredentialRotation queues issuing a new database password to the
+// application's current owner, typically after its ownership changed in tx.
+func (s *ProvisioningService) EnqueueCredentialRotation(tx *gorm.DB, application models.Application, actorId uint) (models.Job, errors.CustomError) {
+	return s.jobService.Enqueue(tx, jobTypeRotateCredentials, applicationJobSubject(application.ID), applicationJobPayload{ApplicationID: application.ID}, &actorId)
+}
+
+// EnqueueStatusApply queues bringing the workloads in line with a suspension
+// or resumption that was committed within tx.
+func (s *ProvisioningService) EnqueueStatusApply(tx *gorm.DB, application models.Application, actorId uint) (models.Job, errors.CustomError) {
+	return s.jobService.Enqueue(tx, jobTypeApplyApplicationStatus, applicationJobSubject(application.ID), applicationJobPayload{ApplicationID: application.ID}, &actorId)
+}
+
+func (s *ProvisioningService) runProvisionJob(payload []byte) error {
+	application, err := s.loadJobApplication(payload)
+	if err != nil {
+		return err
+	}
+
+	// The application was cancelled or deleted while the job was queued.
+	if application.Status != models.ApplicationStatusProvisioning {
+		return nil
+	}
+
+	var owner models.User
+	if err := s.db.First(&owner, application.OwnerID).Error; err != nil {
+		return fmt.Errorf("failed to find owner: %v", err)
+	}
+
+	if err := s.Provision(application, owner); err != nil {
+		return err
+	}
+
+	if err := transitionApplication(s.db, &application, models.ApplicationStatusApproved, ""); err != nil {
+		return err
+	}
+
+	s.notificationService.CreateNotification(application.OwnerID, fmt.Sprintf("Your application %s has been approved", application.Name))
+	return nil
+}
+
+func (s *ProvisioningService) failProvisionJob(payload []byte, jobErr error) {
+	application, err := s.loadJobApplication(payload)
+	if err != nil || application.Status != models.ApplicationStatusProvisioning {
+		return
+	}
+
+	transitionApplication(s.db, &application, models.ApplicationStatusFailed, jobErr.Error())
+	s.notificationService.CreateAdminNotification(fmt.Sprintf("Provisioning of %s failed: %v", application.Name, jobErr))
+}
+
+func (s *ProvisioningService) runTeardownJob(payload []byte) error {
+	application, err := s.loadJobApplication(payload)
+	if err != nil {
+		return err
+	}
+
+	if application.Status != models.ApplicationStatusDeprovisioning {
+		return nil
+	}
+
+	if err := s.Teardown(application); err != nil {
+		return err
+	}
+
+	return transitionApplication(s.db, &application, models.ApplicationStatusPending, "")
+}
+
+func (s *ProvisioningService) runDeleteJob(payload []byte) error {
+	var data applicationJobPayload
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return err
+	}
+
+	application, err := s.loadJobApplication(payload)
+	if err != nil {
+		return err
+	}
+
+	if application.Status != models.ApplicationStatusDeleting {
+		return nil
+	}
+
+	if data.HadResources {
+		if err := s.Teardown(application); err != nil {
+			return err
+		}
+	}
+
+	err = s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("application_id = ?", application.ID).Delete(&models.ApplicationCollaborator{}).Error; err != nil {
+			return errors.Internal("failed to delete collaborators")
+		}
+
+		if err := tx.Unscoped().Where("application_id = ?", application.ID).Delete(&models.WorkloadAlert{}).Error; err != nil {
+			return errors.Internal("failed to delete workload alerts")
+		}
+
+		if err := cancelPendingTransfers(tx, application.ID); err != nil {
+			return err
+		}
+
+		if err := tx.Delete(&application).Error; err != nil {
+			return errors.Internal("failed to delete application")
+		}
+
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	s.notificationService.CreateAdminNotification(fmt.Sprintf("Application deleted: %s", application.Name))
+	return nil
+}
+
+// runRotateCredentialsJob rotates the database password, stores it in Vault
+// under DB_PASSWORD and restarts the workloads so they pick it up before
+// mailing it. A retry after a failed email simply issues a new one.
+func (s *ProvisioningService) runRotateCredentialsJob(payload []byte) error {
+	application, err := s.loadJobApplication(payload)
+	if err != nil {
+		return err
+	}
+
+	if application.Status != models.ApplicationStatusApproved && application.Status != models.ApplicationStatusSuspended {
+		return nil
+	}
+
+	var owner models.User
+	if err := s.db.First(&owner, application.OwnerID).Error; err != nil {
+		return fmt.Errorf("failed to find owner: %v", err)
+	}
+
+	password, err := database.RotateUserPassword(application.Name)
+	if err != nil {
+		return fmt.Errorf("failed to rotate database password: %v", err)
+	}
+
+	secret, err := vault.GetSecret(application.Name)
+	if err != nil {
+		return err
+	}
+	secret[databasePasswordSecretKey] = password
+	if err := vault.UpdateSecret(application.Name, secret); err != nil {
+		return err
+	}
+
+	if kubernetes.NamespaceExists(application.Name) {
+		if err := kubernetes.RestartDeployments(application.Name); err != nil {
+			return fmt.Errorf("failed to restart deployments: %v", err)
+		}
+	}
+
+	if err := email.SendOwnershipTransferEmail(owner.Email, application.Name, password); err != nil {
+		return fmt.Errorf("failed to send email: %v", err)
+	}
+
+	return nil
+}
+
+func (s *ProvisioningService) failRotateCredentialsJob(payload []byte, jobErr error) {
+	application, err := s.loadJobApplication(payload)
+	if err != nil {
+		return
+	}
+
+	s.notificationService.CreateAdminNotification(fmt.Sprintf("Credential rotation of %s failed: %v", application.Name, jobErr))
+}
+
+// runApplyStatusJob scales the workloads for the application's current status,
+// so it also converges when a suspension was lifted before it ran.
+func (s *ProvisioningService) runApplyStatusJob(payload []byte) error {
+	application, err := s.loadJobApplication(payload)
+	if err != nil {
+		return err
+	}
+
+	switch application.Status {
+	case models.ApplicationStatusSuspended:
+		if kubernetes.NamespaceExists(application.Name) {
+			if err := kubernetes.ScaleDownNamespace(application.Name); err != nil {
+				return fmt.Errorf("failed to scale down workloads: %v", err)
+			}
+		}
+	case models.ApplicationStatusApproved:
+		if kubernetes.NamespaceExists(application.Name) {
+			if err := kubernetes.RestoreNamespace(application.Name); err != nil {
+				return fmt.Errorf("failed to restore workloads: %v", err)
+			}
+		}
+	default:
+		return nil
+	}
+
+	// Keep the GitOps values in step so a sync does not bring the workloads
+	// back while suspended.
+	if err := github.TriggerWriteValuesWorkflow(application); err != nil {
+		return fmt.Errorf("failed to trigger GitHub workflow: %v", err)
+	}
+
+	return nil
+}
+
+func (s *ProvisioningService) failApplyStatusJob(payload []byte, jobErr error) {
+	application, err := s.loadJobApplication(payload)
+	if err != nil {
+		return
+	}
+
+	s.notificationService.CreateAdminNotification(fmt.Sprintf("Applying the %s status of %s failed: %v", strings.ToLower(application.Status), application.Name, jobErr))
+}
+
+// failApplicationJob marks an application Failed after its teardown could
+// not be completed, so staff can see the reason and retry.
+func (s *ProvisioningService) failApplicationJob(payload []byte, jobErr error) {
+	application, err := s.loadJobApplication(payload)
+	if err != nil || application.Status == models.ApplicationStatusFailed {
+		return
+	}
+
+	transitionApplication(s.db, &application, models.ApplicationStatusFailed, jobErr.Error())
+	s.notificationService.CreateAdminNotification(fmt.Sprintf("Teardown of %s failed: %v", application.Name, jobErr))
+}
+
+func (s *ProvisioningService) loadJobApplication(payload []byte) (models.Application, error) {
+	var data applicationJobPayload
+	if err := json.Unmarshal(payload, &data); err != nil {
+		return models.Application{}, err
+	}
+
+	var application models.Application
+	if err := s.db.First(&application, data.ApplicationID).Error; err != nil {
+		return models.Application{}, fmt.Errorf("failed to load application %d: %v", data.ApplicationID, err)
+	}
+
+	return application, nil
+}
@@ -0,0 +1,445 @@
+package services
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/injunweb/backend-server/internal/config"
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/database"
+	"github.com/injunweb/backend-server/pkg/email"
+	"github.com/injunweb/backend-server/pkg/errors"
+	"github.com/injunweb/backend-server/pkg/github"
+	"github.com/injunweb/backend-server/pkg/harbor"
+	"github.com/injunweb/backend-server/pkg/kubernetes"
+	"github.com/injunweb/backend-server/pkg/vault"
+
+	"gorm.io/gorm"
+)
+
+const (
+	defaultReconcileMinutes = 60
+
+	jobTypeReconcileCheck  = "reconcile.check"
+	jobTypeReconcileRepair = "reconcile.repair"
+)
+
+const (
+	reconcileResourceVault        = "vault"
+	reconcileResourceDatabase     = "database"
+	reconcileResourceDatabaseUser = "database_user"
+	reconcileResourceHarbor       = "harbor"
+	reconcileResourceNamespace    = "namespace"
+	reconcileResourceQuota        = "quota"
+
+	reconcileProblemMissing  = "missing"
+	reconcileProblemOrphaned = "orphaned"
+)
+
+// reconcileDeletedStatus stands in for the status of soft-deleted
+// applications in drift reports.
+const reconcileDeletedStatus = "Deleted"
+
+// reconcileResourceSteps maps each resource to the provisioning steps that
+// create it. A resource is only reported as orphaned if one of them ran, so
+// names the platform never provisioned are left alone.
+var reconcileResourceSteps = map[string][]string{
+	reconcileResourceVault:        {models.ProvisioningStepVault},
+	reconcileResourceDatabase:     {models.ProvisioningStepDatabase},
+	reconcileResourceDatabaseUser: {models.ProvisioningStepDatabase},
+	reconcileResourceHarbor:       {models.ProvisioningStepGitOps},
+	reconcileResourceNamespace:    {models.ProvisioningStepQuota, models.ProvisioningStepGitOps},
+	reconcileResourceQuota:        {models.ProvisioningStepQuota},
+}
+
+// ReconcileService compares applications against the external systems they
+// are provisioned in. Approved and suspended applications must have every
+// resource; deleted, pending and rejected applications must have none of the
+// resources the platform provisioned for them.
+type ReconcileService struct {
+	db                  *gorm.DB
+	notificationService *NotificationService
+	jobService          *JobService
+
+	mu            sync.Mutex
+	lastSignature string
+}
+
+func NewReconcileService(db *gorm.DB, notificationService *NotificationService, jobService *JobService) *ReconcileService {
+	s := &ReconcileService{db: db, notificationService: notificationService, jobService: jobService}
+
+	jobService.Register(jobTypeReconcileCheck, JobHandler{Run: s.runCheckJob})
+	jobService.Register(jobTypeReconcileRepair, JobHandler{Run: s.runRepairJob})
+
+	minutes, err := strconv.Atoi(config.AppConfig.ReconcileMinutes)
+	if err != nil || minutes <= 0 {
+		minutes = defaultReconcileMinutes
+	}
+	jobService.Schedule(jobTypeReconcileCheck, time.Duration(minutes)*time.Minute)
+
+	return s
+}
+
+type ReconcileDrift struct {
+	ApplicationID uint   `json:"application_id"`
+	Application   string `json:"application"`
+	Status        string `json:"status"`
+	Resource      string `json:"resource"`
+	Problem       string `json:"problem"`
+	Repairable    bool   `json:"repairable"`
+}
+
+type ReconcileReport struct {
+	CheckedAt    string           `json:"checked_at"`
+	Applications int              `json:"applications"`
+	Drift        []ReconcileDrift `json:"drift"`
+	Errors       []string         `json:"errors"`
+}
+
+func (s *ReconcileService) GetReconcileReportByAdmin(actorId uint) (ReconcileReport, errors.CustomError) {
+	if err := authorize(s.db, actorId, models.PermissionApplicationsRead); err != nil {
+		return ReconcileReport{}, err
+	}
+
+	report, err := s.check()
+	if err != nil {
+		return ReconcileReport{}, err
+	}
+
+	return report, nil
+}
+
+type RepairReconcileDriftByAdminResponse struct {
+	JobID   uint   `json:"job_id"`
+	Message string `json:"message"`
+}
+
+func (s *ReconcileService) RepairReconcileDriftByAdmin(actorId uint) (RepairReconcileDriftByAdminResponse, errors.CustomError) {
+	if err := authorize(s.db, actorId, models.PermissionApplicationsManage); err != nil {
+		return RepairReconcileDriftByAdminResponse{}, err
+	}
+
+	job, err := s.jobService.Enqueue(s.db, jobTypeReconcileRepair, "reconcile", struct{}{}, &actorId)
+	if err != nil {
+		return RepairReconcileDriftByAdminResponse{}, err
+	}
+
+	return RepairReconcileDriftByAdminResponse{
+		JobID:   job.ID,
+		Message: "Drift repair started",
+	}, nil
+}
+
+func (s *ReconcileService) runCheckJob(payload []byte) error {
+	report, err := s.check()
+	if err != nil {
+		return fmt.Errorf("%s", err.GetMessage())
+	}
+
+	// Missing quotas are restored right away since applying one is harmless;
+	// everything else waits for an admin to start a repair.
+	var remaining []ReconcileDrift
+	for _, drift := range report.Drift {
+		if drift.Resource == reconcileResourceQuota && drift.Problem == reconcileProblemMissing {
+			if application, current := s.reloadDrifted(drift); current {
+				repairErr := s.repair(application, drift)
+				if repairErr == nil {
+					continue
+				}
+				log.Printf("Failed to apply quota of %s: %v", drift.Application, repairErr)
+			}
+		}
+		remaining = append(remaining, drift)
+	}
+	report.Drift = remaining
+
+	// Only notify when the drift changed since the last check, so a
+	// long-standing problem is not reported every interval.
+	signature := driftSignature(report.Drift)
+	s.mu.Lock()
+	changed := signature != s.lastSignature
+	s.lastSignature = signature
+	s.mu.Unlock()
+
+	if changed && len(report.Drift) > 0 {
+		s.notificationService.CreateAdminNotification(fmt.Sprintf("Reconciliation found %d drifted resources: %s", len(report.Drift), summarizeDrift(report.Drift)))
+	}
+
+	return nil
+}
+
+func (s *ReconcileService) runRepairJob(payload []byte) error {
+	report, err := s.check()
+	if err != nil {
+		return fmt.Errorf("%s", err.GetMessage())
+	}
+
+	var repaired, failed []ReconcileDrift
+	for _, drift := range report.Drift {
+		if !drift.Repairable {
+			continue
+		}
+
+		// The application may have moved on since the check, in which case
+		// the drift no longer applies.
+		application, current := s.reloadDrifted(drift)
+		if !current {
+			log.Printf("Skipping repair of %s %s of %s: application changed", drift.Problem, drift.Resource, drift.Application)
+			continue
+		}
+
+		if repairErr := s.repair(application, drift); repairErr != nil {
+			log.Printf("Failed to repair %s %s of %s: %v", drift.Problem, drift.Resource, drift.Application, repairErr)
+			failed = append(failed, drift)
+			continue
+		}
+		repaired = append(repaired, drift)
+	}
+
+	if len(repaired) > 0 {
+		s.notificationService.CreateAdminNotification(fmt.Sprintf("Reconciliation repaired %d resources: %s", len(repaired), summarizeDrift(repaired)))
+	}
+	if len(failed) > 0 {
+		return fmt.Errorf("failed to repair %d resources: %s", len(failed), summarizeDrift(failed))
+	}
+
+	return nil
+}
+
+// check builds the drift report.
+func (s *ReconcileService) check() (ReconcileReport, errors.CustomError) {
+	var live []models.Application
+	if err := s.db.Find(&live).Error; err != nil {
+		return ReconcileReport{}, errors.Internal("failed to retrieve applications")
+	}
+
+	var deleted []models.Application
+	if err := s.db.Unscoped().Where("deleted_at IS NOT NULL").Order("deleted_at DESC").Find(&deleted).Error; err != nil {
+		return ReconcileReport{}, errors.Internal("failed to retrieve deleted applications")
+	}
+
+	var steps []models.ProvisioningStep
+	if err := s.db.Where("status <> ?", models.ProvisioningStepStatusPending).Find(&steps).Error; err != nil {
+		return ReconcileReport{}, errors.Internal("failed to retrieve provisioning steps")
+	}
+	provisioned := make(map[uint]map[string]bool)
+	for _, step := range steps {
+		if provisioned[step.ApplicationID] == nil {
+			provisioned[step.ApplicationID] = make(map[string]bool)
+		}
+		provisioned[step.ApplicationID][step.Step] = true
+	}
+
+	report := ReconcileReport{CheckedAt: time.Now().Format("2006-01-02 15:04:05")}
+	names := make(map[string]bool)
+
+	for _, application := range live {
+		names[application.Name] = true
+
+		var expected bool
+		switch application.Status {
+		case models.ApplicationStatusApproved, models.ApplicationStatusSuspended:
+			expected = true
+		case models.ApplicationStatusPending, models.ApplicationStatusRejected:
+			expected = false
+		default:
+			// Provisioning, Deprovisioning, Failed and Deleting applications
+			// are in the middle of a job and are left alone.
+			continue
+		}
+
+		report.Applications++
+		s.checkApplication(&report, application, application.Status, expected, provisioned[application.ID])
+	}
+
+	// Names can be reused, so only the most recent deleted application with a
+	// name that is no longer taken is checked for leftovers.
+	for _, application := range deleted {
+		if names[application.Name] {
+			continue
+		}
+		names[application.Name] = true
+
+		report.Applications++
+		s.checkApplication(&report, application, reconcileDeletedStatus, false, provisioned[application.ID])
+	}
+
+	return report, nil
+}
+
+// reloadDrifted loads the application behind a drift entry and reports
+// whether it is still in the state the drift was found in, with no job
+// working on it.
+func (s *ReconcileService) reloadDrifted(drift ReconcileDrift) (models.Application, bool) {
+	var application models.Application
+	if err := s.db.Unscoped().Preload("Owner").First(&application, drift.ApplicationID).Error; err != nil {
+		return models.Application{}, false
+	}
+
+	status := application.Status
+	if application.DeletedAt.Valid {
+		status = reconcileDeletedStatus
+
+		var taken int64
+		if err := s.db.Model(&models.Application{}).Where("name = ?", application.Name).Count(&taken).Error; err != nil || taken > 0 {
+			return models.Application{}, false
+		}
+	}
+	if status != drift.Status {
+		return models.Application{}, false
+	}
+
+	var active int64
+	err := s.db.Model(&models.Job{}).
+		Where("subject = ? AND status IN ?", applicationJobSubject(application.ID), []string{models.JobStatusQueued, models.JobStatusRunning}).
+		Count(&active).Error
+	if err != nil || active > 0 {
+		return models.Application{}, false
+	}
+
+	return application, true
+}
+
+func (s *ReconcileService) checkApplication(report *ReconcileReport, application models.Application, status string, expected bool, provisioned map[string]bool) {
+	present := make(map[string]bool)
+	var checkErr error
+
+	if present[reconcileResourceVault], checkErr = vault.SecretExists(application.Name); checkErr != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", application.Name, checkErr))
+		return
+	}
+
+	if present[reconcileResourceDatabase], present[reconcileResourceDatabaseUser], checkErr = database.DatabaseAndUserExist(application.Name); checkErr != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", application.Name, checkErr))
+		return
+	}
+
+	if present[reconcileResourceHarbor], checkErr = harbor.RepositoryExists(application.Name); checkErr != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", application.Name, checkErr))
+		return
+	}
+
+	if present[reconcileResourceNamespace], checkErr = kubernetes.CheckNamespace(application.Name); checkErr != nil {
+		report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", application.Name, checkErr))
+		return
+	}
+
+	resources := []string{
+		reconcileResourceVault,
+		reconcileResourceDatabase,
+		reconcileResourceDatabaseUser,
+		reconcileResourceHarbor,
+		reconcileResourceNamespace,
+	}
+
+	// The quota goes away with the namespace, so it is only checked where one
+	// is expected. This also covers applications approved before quotas.
+	if expected {
+		if present[reconcileResourceQuota], checkErr = kubernetes.QuotaExists(application.Name); checkErr != nil {
+			report.Errors = append(report.Errors, fmt.Sprintf("%s: %v", application.Name, checkErr))
+			return
+		}
+		resources = append(resources, reconcileResourceQuota)
+	}
+	for _, resource := range resources {
+		if present[resource] == expected {
+			continue
+		}
+		if !expected && !provisionedAny(provisioned, reconcileResourceSteps[resource]) {
+			continue
+		}
+
+		drift := ReconcileDrift{
+			ApplicationID: application.ID,
+			Application:   application.Name,
+			Status:        status,
+			Resource:      resource,
+			Problem:       reconcileProblemOrphaned,
+			Repairable:    true,
+		}
+		if expected {
+			drift.Problem = reconcileProblemMissing
+			// Images only appear after the application's pipeline has
+			// built and pushed them.
+			drift.Repairable = resource != reconcileResourceHarbor
+		}
+		report.Drift = append(report.Drift, drift)
+	}
+}
+
+func (s *ReconcileService) repair(application models.Application, drift ReconcileDrift) error {
+	if drift.Problem == reconcileProblemOrphaned {
+		switch drift.Resource {
+		case reconcileResourceVault:
+			return vault.DeleteSecret(application.Name)
+		case reconcileResourceDatabase, reconcileResourceDatabaseUser:
+			return database.DeleteDatabaseAndUser(application.Name)
+		case reconcileResourceHarbor:
+			return harbor.DeleteRepository(application.Name)
+		case reconcileResourceNamespace:
+			return kubernetes.DeleteNamespace(application.Name)
+		}
+		return nil
+	}
+
+	switch drift.Resource {
+	case reconcileResourceVault:
+		return vault.InitSecret(application.Name, map[string]interface{}{"INIT": "INIT"})
+	case reconcileResourceDatabase:
+		_, err := database.CreateDatabaseAndUser(application.Name)
+		return err
+	case reconcileResourceDatabaseUser:
+		// The user may have been recreated by the database repair already, so
+		// rotate to a password that is known to be set.
+		if _, err := database.CreateDatabaseAndUser(application.Name); err != nil {
+			return err
+		}
+		password, err := database.RotateUserPassword(application.Name)
+		if err != nil {
+			return err
+		}
+		return email.SendDatabaseRecreatedEmail(application.Owner.Email, application.Name, password)
+	case reconcileResourceNamespace:
+		// Recreate the namespace with its quota before the workloads are
+		// synced back into it.
+		if err := applyApplicationQuota(application); err != nil {
+			return err
+		}
+		return github.TriggerWriteValuesWorkflow(application)
+	case reconcileResourceQuota:
+		return applyApplicationQuota(application)
+	}
+
+	return nil
+}
+
+func provisionedAny(provisioned map[string]bool, steps []string) bool {
+	for _, step := range steps {
+		if provisioned[step] {
+			return true
+		}
+	}
+	return false
+}
+
+func driftSignature(drift []ReconcileDrift) string {
+	var keys []string
+	for _, d := range drift {
+		keys = append(keys, fmt.Sprintf("%d/%s/%s", d.ApplicationID, d.Resource, d.Problem))
+	}
+	sort.Strings(keys)
+	return strings.Join(keys, ",")
+}
+
+func summarizeDrift(drift []ReconcileDrift) string {
+	var parts []string
+	for _, d := range drift {
+		parts = append(parts, fmt.Sprintf("%s %s %s", d.Application, d.Resource, d.Problem))
+	}
+	return strings.Join(parts, ", ")
+}
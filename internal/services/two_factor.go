@@ -0,0 +1,370 @@
+package services
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/injunweb/backend-server/internal/models"
+	"github.com/injunweb/backend-server/pkg/errors"
+	"github.com/injunweb/backend-server/pkg/token"
+	"github.com/injunweb/backend-server/pkg/totp"
+
+	"golang.org/x/crypto/bcrypt"
+	"gorm.io/gorm"
+)
+
+const (
+	totpIssuer        = "injunweb"
+	recoveryCodeCount = 10
+)
+
+type SetupTwoFactorResponse struct {
+	Secret          string `json:"secret"`
+	ProvisioningURI string `json:"provisioning_uri"`
+	Message         string `json:"message"`
+}
+
+func (s *AuthService) SetupTwoFactor(userId uint) (SetupTwoFactorResponse, errors.CustomError) {
+	var response SetupTwoFactorResponse
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userId).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		if user.TOTPEnabled {
+			return errors.Conflict("two-factor authentication already enabled")
+		}
+
+		secret, err := totp.GenerateSecret()
+		if err != nil {
+			return errors.Internal("failed to generate two-factor secret")
+		}
+
+		user.TOTPSecret = secret
+		user.TOTPLastStep = 0
+		if err := tx.Save(&user).Error; err != nil {
+			return errors.Internal("failed to save two-factor secret")
+		}
+
+		response = SetupTwoFactorResponse{
+			Secret:          secret,
+			ProvisioningURI: totp.ProvisioningURI(totpIssuer, user.Username, secret),
+			Message:         "Scan the provisioning URI with an authenticator app and confirm with a code",
+		}
+		return nil
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return SetupTwoFactorResponse{}, customErr
+		}
+		return SetupTwoFactorResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	return response, nil
+}
+
+type ConfirmTwoFactorRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type ConfirmTwoFactorResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	Message       string   `json:"message"`
+}
+
+func (s *AuthService) ConfirmTwoFactor(userId uint, req ConfirmTwoFactorRequest) (ConfirmTwoFactorResponse, errors.CustomError) {
+	var recoveryCodes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userId).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		if user.TOTPEnabled {
+			return errors.Conflict("two-factor authentication already enabled")
+		}
+
+		if user.TOTPSecret == "" {
+			return errors.BadRequest("two-factor setup has not been started")
+		}
+
+		step, ok := totp.Validate(user.TOTPSecret, req.Code, time.Now(), user.TOTPLastStep)
+		if !ok {
+			return errors.BadRequest("invalid two-factor code")
+		}
+
+		user.TOTPEnabled = true
+		user.TOTPLastStep = step
+		if err := tx.Save(&user).Error; err != nil {
+			return errors.Internal("failed to enable two-factor authentication")
+		}
+
+		var customErr errors.CustomError
+		recoveryCodes, customErr = replaceRecoveryCodes(tx, user.ID)
+		return customErr
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return ConfirmTwoFactorResponse{}, customErr
+		}
+		return ConfirmTwoFactorResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	s.notificationService.CreateNotification(userId, "Two-factor authentication was enabled on your account")
+
+	return ConfirmTwoFactorResponse{
+		RecoveryCodes: recoveryCodes,
+		Message:       "Two-factor authentication enabled, store the recovery codes somewhere safe",
+	}, nil
+}
+
+type RegenerateRecoveryCodesRequest struct {
+	Code string `json:"code" binding:"required"`
+}
+
+type RegenerateRecoveryCodesResponse struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+	Message       string   `json:"message"`
+}
+
+func (s *AuthService) RegenerateRecoveryCodes(userId uint, req RegenerateRecoveryCodesRequest) (RegenerateRecoveryCodesResponse, errors.CustomError) {
+	var recoveryCodes []string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userId).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		if !user.TOTPEnabled {
+			return errors.BadRequest("two-factor authentication not enabled")
+		}
+
+		if err := verifyTOTPCode(tx, &user, req.Code); err != nil {
+			return err
+		}
+
+		var customErr errors.CustomError
+		recoveryCodes, customErr = replaceRecoveryCodes(tx, user.ID)
+		return customErr
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return RegenerateRecoveryCodesResponse{}, customErr
+		}
+		return RegenerateRecoveryCodesResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	return RegenerateRecoveryCodesResponse{
+		RecoveryCodes: recoveryCodes,
+		Message:       "Recovery codes regenerated",
+	}, nil
+}
+
+type DisableTwoFactorRequest struct {
+	Password string `json:"password" binding:"required"`
+	Code     string `json:"code" binding:"required"`
+}
+
+type DisableTwoFactorResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *AuthService) DisableTwoFactor(userId uint, sessionId uint, req DisableTwoFactorRequest) (DisableTwoFactorResponse, errors.CustomError) {
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var user models.User
+		if err := tx.First(&user, userId).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		if !user.TOTPEnabled {
+			return errors.BadRequest("two-factor authentication not enabled")
+		}
+
+		if err := bcrypt.CompareHashAndPassword([]byte(user.Password), []byte(req.Password)); err != nil {
+			return errors.Unauthorized("password is incorrect")
+		}
+
+		if err := verifySecondFactor(tx, &user, req.Code); err != nil {
+			return err
+		}
+
+		user.TOTPEnabled = false
+		user.TOTPSecret = ""
+		user.TOTPLastStep = 0
+		if err := tx.Save(&user).Error; err != nil {
+			return errors.Internal("failed to disable two-factor authentication")
+		}
+
+		if err := tx.Where("user_id = ?", user.ID).Delete(&models.RecoveryCode{}).Error; err != nil {
+			return errors.Internal("failed to delete recovery codes")
+		}
+
+		return revokeUserSessions(tx, user.ID, sessionId)
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return DisableTwoFactorResponse{}, customErr
+		}
+		return DisableTwoFactorResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	s.notificationService.CreateNotification(userId, "Two-factor authentication was disabled on your account")
+
+	return DisableTwoFactorResponse{
+		Message: "Two-factor authentication disabled",
+	}, nil
+}
+
+type LoginTwoFactorRequest struct {
+	ChallengeToken string `json:"challenge_token" binding:"required"`
+	Code           string `json:"code" binding:"required"`
+}
+
+func (s *AuthService) LoginTwoFactor(req LoginTwoFactorRequest, ipAddress string, userAgent string) (LoginResponse, errors.CustomError) {
+	claims, customErr := parseClaims(req.ChallengeToken, tokenTypeTwoFactorChallenge)
+	if customErr != nil {
+		return LoginResponse{}, errors.Unauthorized("invalid or expired challenge token")
+	}
+
+	userId, ok := claims["user_id"].(float64)
+	if !ok {
+		return LoginResponse{}, errors.Unauthorized("invalid challenge token claims")
+	}
+
+	var user models.User
+	if err := s.db.First(&user, uint(userId)).Error; err != nil {
+		return LoginResponse{}, errors.Unauthorized("invalid credentials")
+	}
+
+	if err := s.loginLimiter.Check(user.Username, ipAddress); err != nil {
+		return LoginResponse{}, err
+	}
+
+	var response LoginResponse
+	invalidCode := false
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&user, user.ID).Error; err != nil {
+			return errors.Unauthorized("invalid credentials")
+		}
+
+		if !user.TOTPEnabled {
+			return errors.BadRequest("two-factor authentication not enabled")
+		}
+
+		if err := verifySecondFactor(tx, &user, req.Code); err != nil {
+			invalidCode = err.GetStatus() == http.StatusUnauthorized
+			return err
+		}
+
+		accessToken, refreshToken, err := s.createSession(tx, user, ipAddress, userAgent)
+		if err != nil {
+			return err
+		}
+
+		response = LoginResponse{
+			Token:        accessToken,
+			RefreshToken: refreshToken,
+			ExpiresIn:    int64(accessTokenTTL().Seconds()),
+			Message:      "Login successful",
+		}
+		return nil
+	})
+
+	if invalidCode {
+		s.recordLoginFailure(user.Username, ipAddress)
+	}
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return LoginResponse{}, customErr
+		}
+		return LoginResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	s.loginLimiter.Succeed(user.Username)
+
+	return response, nil
+}
+
+func (s *AuthService) IsTwoFactorEnabled(userId uint) bool {
+	var user models.User
+	if err := s.db.Select("totp_enabled").First(&user, userId).Error; err != nil {
+		return false
+	}
+
+	return user.TOTPEnabled
+}
+
+// verifySecondFactor accepts either a current TOTP code or an unused recovery code.
+func verifySecondFactor(tx *gorm.DB, user *models.User, code string) errors.CustomError {
+	if err := verifyTOTPCode(tx, user, code); err == nil {
+		return nil
+	}
+
+	var recoveryCode models.RecoveryCode
+	err := tx.Where("user_id = ? AND code_hash = ? AND used_at IS NULL", user.ID, token.Hash(normalizeRecoveryCode(code))).
+		First(&recoveryCode).Error
+	if err != nil {
+		return errors.Unauthorized("invalid two-factor code")
+	}
+
+	now := time.Now()
+	recoveryCode.UsedAt = &now
+	if err := tx.Save(&recoveryCode).Error; err != nil {
+		return errors.Internal("failed to consume recovery code")
+	}
+
+	return nil
+}
+
+func verifyTOTPCode(tx *gorm.DB, user *models.User, code string) errors.CustomError {
+	step, ok := totp.Validate(user.TOTPSecret, code, time.Now(), user.TOTPLastStep)
+	if !ok {
+		return errors.Unauthorized("invalid two-factor code")
+	}
+
+	user.TOTPLastStep = step
+	if err := tx.Model(user).Update("totp_last_step", step).Error; err != nil {
+		return errors.Internal("failed to record two-factor code usage")
+	}
+
+	return nil
+}
+
+func replaceRecoveryCodes(tx *gorm.DB, userId uint) ([]string, errors.CustomError) {
+	if err := tx.Where("user_id = ?", userId).Delete(&models.RecoveryCode{}).Error; err != nil {
+		return nil, errors.Internal("failed to delete recovery codes")
+	}
+
+	codes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		raw, err := token.Generate(5)
+		if err != nil {
+			return nil, errors.Internal("failed to generate recovery codes")
+		}
+
+		code := raw[:5] + "-" + raw[5:]
+		record := models.RecoveryCode{
+			UserID:   userId,
+			CodeHash: token.Hash(normalizeRecoveryCode(code)),
+		}
+		if err := tx.Create(&record).Error; err != nil {
+			return nil, errors.Internal("failed to save recovery codes")
+		}
+
+		codes = append(codes, code)
+	}
+
+	return codes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.ReplaceAll(strings.TrimSpace(code), "-", ""))
+}
@@ -19,10 +19,13 @@ func NewUserService(db *gorm.DB) *UserService {
 }
 
 type GetUserResponse struct {
-	ID       uint   `json:"id"`
-	Username string `json:"username"`
-	Email    string `json:"email"`
-	IsAdmin  bool   `json:"is_admin"`
+	ID            uint   `json:"id"`
+	Username      string `json:"username"`
+	Email         string `json:"email"`
+	EmailVerified bool   `json:"email_verified"`
+	GithubLogin   string `json:"github_login"`
+	Role          string `json:"role"`
+	IsAdmin       bool   `json:"is_admin"`
 }
 
 func (s *UserService) GetUser(userId uint) (GetUserResponse, errors.CustomError) {
@@ -32,10 +35,13 @@ func (s *UserService) GetUser(userId uint) (GetUserResponse, errors.CustomError)
 	}
 
 	return GetUserResponse{
-		ID:       user.ID,
-		Username: user.Username,
-		Email:    user.Email,
-		IsAdmin:  user.IsAdmin,
+		ID:            user.ID,
+		Username:      user.Username,
+		Email:         user.Email,
+		EmailVerified: user.IsEmailVerified(),
+		GithubLogin:   user.GithubLogin,
+		Role:          user.Role,
+		IsAdmin:       models.IsStaffRole(user.Role),
 	}, nil
 }
 
@@ -49,8 +55,9 @@ type UpdateUserResponse struct {
 }
 
 func (s *UserService) UpdateUser(userId uint, req UpdateUserRequest) (UpdateUserResponse, errors.CustomError) {
+	var user models.User
+	var verificationToken string
 	err := s.db.Transaction(func(tx *gorm.DB) error {
-		var user models.User
 		if err := tx.First(&user, userId).Error; err != nil {
 			return errors.NotFound("user not found")
 		}
@@ -62,13 +69,25 @@ func (s *UserService) UpdateUser(userId uint, req UpdateUserRequest) (UpdateUser
 			return errors.BadRequest("invalid username")
 		}
 
+		emailChanged := user.Email != req.Email
 		user.Email = req.Email
 		user.Username = req.Username
+		if emailChanged {
+			user.EmailVerifiedAt = nil
+		}
 
 		if err := tx.Save(&user).Error; err != nil {
 			return errors.Internal("failed to update user")
 		}
 
+		if emailChanged {
+			var customErr errors.CustomError
+			verificationToken, customErr = createEmailVerificationToken(tx, user)
+			if customErr != nil {
+				return customErr
+			}
+		}
+
 		return nil
 	})
 
@@ -79,11 +98,57 @@ func (s *UserService) UpdateUser(userId uint, req UpdateUserRequest) (UpdateUser
 		return UpdateUserResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
 	}
 
+	if verificationToken != "" {
+		sendVerificationEmail(user, verificationToken)
+		return UpdateUserResponse{
+			Message: "User updated successfully, please verify your new email address",
+		}, nil
+	}
+
 	return UpdateUserResponse{
 		Message: "User updated successfully",
 	}, nil
 }
 
+type ResendVerificationEmailResponse struct {
+	Message string `json:"message"`
+}
+
+func (s *UserService) ResendVerificationEmail(userId uint) (ResendVerificationEmailResponse, errors.CustomError) {
+	var user models.User
+	var verificationToken string
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		if err := tx.First(&user, userId).Error; err != nil {
+			return errors.NotFound("user not found")
+		}
+
+		if user.IsEmailVerified() {
+			return errors.BadRequest("email already verified")
+		}
+
+		var customErr errors.CustomError
+		verificationToken, customErr = createEmailVerificationToken(tx, user)
+		if customErr != nil {
+			return customErr
+		}
+
+		return nil
+	})
+
+	if err != nil {
+		if customErr, ok := err.(errors.CustomError); ok {
+			return ResendVerificationEmailResponse{}, customErr
+		}
+		return ResendVerificationEmailResponse{}, errors.Internal(fmt.Sprintf("transaction failed: %v", err))
+	}
+
+	sendVerificationEmail(user, verificationToken)
+
+	return ResendVerificationEmailResponse{
+		Message: "Verification email sent",
+	}, nil
+}
+
 func (s *UserService) AddSubscription(userID uint, endpoint, p256dh, auth string) errors.CustomError {
 	err := s.db.Transaction(func(tx *gorm.DB) error {
 		subscription := models.Subscription{
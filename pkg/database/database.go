@@ -29,15 +29,76 @@ func Init() error {
 		return fmt.Errorf("failed to connect to database: %v", err)
 	}
 
-	err = DB.AutoMigrate(&models.User{}, &models.Application{}, &models.ExtraHostnames{}, &models.Notification{}, &models.Subscription{})
+	// Accounts created before email verification existed are treated as
+	// verified; this is only known before the column is added.
+	backfillVerification := DB.Migrator().HasTable(&models.User{}) &&
+		!DB.Migrator().HasColumn(&models.User{}, "email_verified_at")
+
+	err = DB.AutoMigrate(
+		&models.User{},
+		&models.Application{},
+		&models.ExtraHostnames{},
+		&models.Notification{},
+		&models.Subscription{},
+		&models.Session{},
+		&models.PasswordResetToken{},
+		&models.EmailVerificationToken{},
+		&models.RecoveryCode{},
+		&models.PersonalAccessToken{},
+		&models.LoginAttempt{},
+		&models.AuditLog{},
+		&models.Organization{},
+		&models.OrganizationMember{},
+		&models.OrganizationInvitation{},
+		&models.ApplicationCollaborator{},
+		&models.ApplicationTransfer{},
+		&models.ProvisioningStep{},
+		&models.Job{},
+		&models.WorkloadAlert{},
+	)
 	if err != nil {
 		return fmt.Errorf("failed to migrate database: %v", err)
 	}
 
+	if err := migrateAdminRoles(); err != nil {
+		return fmt.Errorf("failed to migrate admin roles: %v", err)
+	}
+
+	if backfillVerification {
+		if err := backfillEmailVerification(); err != nil {
+			return fmt.Errorf("failed to backfill email verification: %v", err)
+		}
+	}
+
 	log.Println("Database connection established and migrations completed")
 	return nil
 }
 
+// migrateAdminRoles converts the legacy is_admin flag into the superadmin role
+// and drops the column once every admin has been carried over.
+func migrateAdminRoles() error {
+	if !DB.Migrator().HasColumn(&models.User{}, "is_admin") {
+		return nil
+	}
+
+	err := DB.Model(&models.User{}).Unscoped().
+		Where("is_admin = ? AND role = ?", true, models.RoleUser).
+		Update("role", models.RoleSuperadmin).Error
+	if err != nil {
+		return err
+	}
+
+	return DB.Migrator().DropColumn(&models.User{}, "is_admin")
+}
+
+// backfillEmailVerification marks every existing account as verified at the
+// time it was created.
+func backfillEmailVerification() error {
+	return DB.Model(&models.User{}).Unscoped().
+		Where("email_verified_at IS NULL").
+		Update("email_verified_at", gorm.Expr("created_at")).Error
+}
+
 func CreateDatabaseAndUser(appName string) (string, error) {
 	password := generateRandomPassword()
 
@@ -48,6 +109,7 @@ func CreateDatabaseAndUser(appName string) (string, error) {
 	if err != nil {
 		return "", fmt.Errorf("failed to connect to root database: %v", err)
 	}
+	defer closeRootDB(rootDb)
 
 	queries := []string{
 		fmt.Sprintf("CREATE DATABASE IF NOT EXISTS `%s`;", appName),
@@ -76,6 +138,32 @@ func generateRandomPassword() string {
 	return string(password)
 }
 
+func RotateUserPassword(appName string) (string, error) {
+	password := generateRandomPassword()
+
+	rootDsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/?charset=utf8mb4&parseTime=True&loc=Local",
+		"root", config.AppConfig.DBRootPassword, config.AppConfig.DBHost, config.AppConfig.DBPort)
+
+	rootDb, err := gorm.Open(mysql.Open(rootDsn), &gorm.Config{})
+	if err != nil {
+		return "", fmt.Errorf("failed to connect to root database: %v", err)
+	}
+	defer closeRootDB(rootDb)
+
+	queries := []string{
+		fmt.Sprintf("ALTER USER '%s'@'%%' IDENTIFIED BY '%s';", appName, password),
+		"FLUSH PRIVILEGES;",
+	}
+
+	for _, query := range queries {
+		if err := rootDb.Exec(query).Error; err != nil {
+			return "", fmt.Errorf("failed to execute query: %v", err)
+		}
+	}
+
+	return password, nil
+}
+
 func DeleteDatabaseAndUser(appName string) error {
 	rootDsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/?charset=utf8mb4&parseTime=True&loc=Local",
 		"root", config.AppConfig.DBRootPassword, config.AppConfig.DBHost, config.AppConfig.DBPort)
@@ -84,6 +172,7 @@ func DeleteDatabaseAndUser(appName string) error {
 	if err != nil {
 		return fmt.Errorf("failed to connect to root database: %v", err)
 	}
+	defer closeRootDB(rootDb)
 
 	queries := []string{
 		fmt.Sprintf("DROP DATABASE IF EXISTS `%s`;", appName),
@@ -99,3 +188,35 @@ func DeleteDatabaseAndUser(appName string) error {
 
 	return nil
 }
+
+// DatabaseAndUserExist reports separately whether the application's database
+// and its MySQL user exist.
+func DatabaseAndUserExist(appName string) (bool, bool, error) {
+	rootDsn := fmt.Sprintf("%s:%s@tcp(%s:%s)/?charset=utf8mb4&parseTime=True&loc=Local",
+		"root", config.AppConfig.DBRootPassword, config.AppConfig.DBHost, config.AppConfig.DBPort)
+
+	rootDb, err := gorm.Open(mysql.Open(rootDsn), &gorm.Config{})
+	if err != nil {
+		return false, false, fmt.Errorf("failed to connect to root database: %v", err)
+	}
+	defer closeRootDB(rootDb)
+
+	var databases int64
+	if err := rootDb.Raw("SELECT COUNT(*) FROM information_schema.SCHEMATA WHERE SCHEMA_NAME = ?", appName).Scan(&databases).Error; err != nil {
+		return false, false, fmt.Errorf("failed to execute query: %v", err)
+	}
+
+	var users int64
+	if err := rootDb.Raw("SELECT COUNT(*) FROM mysql.user WHERE User = ?", appName).Scan(&users).Error; err != nil {
+		return false, false, fmt.Errorf("failed to execute query: %v", err)
+	}
+
+	return databases > 0, users > 0, nil
+}
+
+// closeRootDB releases the connection pool opened for a single root operation.
+func closeRootDB(rootDb *gorm.DB) {
+	if sqlDB, err := rootDb.DB(); err == nil {
+		sqlDB.Close()
+	}
+}
@@ -23,11 +23,94 @@ func SendApprovalEmail(toEmail, appName, dbPassword string) error {
 		toEmail, config.AppConfig.DBHost, config.AppConfig.DBPort, appName, appName, dbPassword,
 	)
 
+	return send(toEmail, "Application Approved", msg)
+}
+
+func SendOwnershipTransferEmail(toEmail, appName, dbPassword string) error {
+	msg := fmt.Sprintf(
+		"You are now the owner of the application %s.\r\n\r\n"+
+			"The database password was rotated during the transfer. The new password has been stored in the application's environment as DB_PASSWORD and the application was restarted to pick it up. Read the password from that variable; a copy kept under any other name no longer works.\r\n\r\n"+
+			"Database Type: mysql\r\n"+
+			"Database Host: %s\r\n"+
+			"Database Port: %s\r\n"+
+			"Database Name: %s\r\n"+
+			"Database User: %s\r\n"+
+			"Database Password: %s\r\n",
+		appName, config.AppConfig.DBHost, config.AppConfig.DBPort, appName, appName, dbPassword,
+	)
+
+	return send(toEmail, "Application Ownership Transferred", msg)
+}
+
+func SendDatabaseRecreatedEmail(toEmail, appName, dbPassword string) error {
+	msg := fmt.Sprintf(
+		"The database user of your application %s was missing and has been recreated.\r\n\r\n"+
+			"Update the application's environment with the new credentials.\r\n\r\n"+
+			"Database Type: mysql\r\n"+
+			"Database Host: %s\r\n"+
+			"Database Port: %s\r\n"+
+			"Database Name: %s\r\n"+
+			"Database User: %s\r\n"+
+			"Database Password: %s\r\n",
+		appName, config.AppConfig.DBHost, config.AppConfig.DBPort, appName, appName, dbPassword,
+	)
+
+	return send(toEmail, "Application Database Recreated", msg)
+}
+
+func SendPasswordResetEmail(toEmail, resetLink string, validMinutes int) error {
+	msg := fmt.Sprintf(
+		"A password reset was requested for your injunweb account.\r\n\r\n"+
+			"Reset your password: %s\r\n\r\n"+
+			"This link expires in %d minutes and can only be used once.\r\n"+
+			"If you did not request a password reset, you can ignore this email.\r\n",
+		resetLink, validMinutes,
+	)
+
+	return send(toEmail, "Password Reset", msg)
+}
+
+func SendVerificationEmail(toEmail, verificationLink string, validHours int) error {
+	msg := fmt.Sprintf(
+		"Please confirm that this address belongs to your injunweb account.\r\n\r\n"+
+			"Verify your email: %s\r\n\r\n"+
+			"This link expires in %d hours.\r\n"+
+			"If you did not sign up for injunweb, you can ignore this email.\r\n",
+		verificationLink, validHours,
+	)
+
+	return send(toEmail, "Verify Your Email Address", msg)
+}
+
+func SendPasswordChangedEmail(toEmail, username string) error {
+	msg := fmt.Sprintf(
+		"The password for your injunweb account %s was just changed.\r\n\r\n"+
+			"All other sessions have been signed out.\r\n"+
+			"If you did not make this change, reset your password immediately and contact an administrator.\r\n",
+		username,
+	)
+
+	return send(toEmail, "Your Password Was Changed", msg)
+}
+
+func SendOrganizationInvitationEmail(toEmail, organizationName, inviterName, invitationLink string, validDays int) error {
+	msg := fmt.Sprintf(
+		"%s invited you to join the %s organization on injunweb.\r\n\r\n"+
+			"Accept the invitation: %s\r\n\r\n"+
+			"This link expires in %d days. Sign in with the account that uses this email address to accept.\r\n"+
+			"If you were not expecting this invitation, you can ignore this email.\r\n",
+		inviterName, organizationName, invitationLink, validDays,
+	)
+
+	return send(toEmail, "Organization Invitation", msg)
+}
+
+func send(toEmail, subject, body string) error {
 	m := gomail.NewMessage()
 	m.SetHeader("From", config.AppConfig.SMTPSenderEmail)
 	m.SetHeader("To", toEmail)
-	m.SetHeader("Subject", "Application Approved")
-	m.SetBody("text/plain", msg)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/plain", body)
 
 	port, err := strconv.Atoi(config.AppConfig.SMTPPort)
 	if err != nil {
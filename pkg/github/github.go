@@ -5,6 +5,7 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strconv"
 
 	"github.com/injunweb/backend-server/internal/config"
 	"github.com/injunweb/backend-server/internal/models"
@@ -19,6 +20,7 @@ func TriggerWriteValuesWorkflow(app models.Application) error {
 			"branch":          app.Branch,
 			"port":            app.Port,
 			"primaryHostname": app.PrimaryHostname,
+			"replicas":        strconv.Itoa(app.DesiredReplicas()),
 		},
 	}
 
@@ -0,0 +1,158 @@
+package github
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/injunweb/backend-server/internal/config"
+)
+
+const (
+	defaultOAuthURL = "https://github.com"
+	defaultAPIURL   = "https://api.github.com"
+	oauthScope      = "read:user user:email"
+)
+
+type OAuthUser struct {
+	ID    int64  `json:"id"`
+	Login string `json:"login"`
+	Email string `json:"email"`
+}
+
+type oauthEmail struct {
+	Email    string `json:"email"`
+	Primary  bool   `json:"primary"`
+	Verified bool   `json:"verified"`
+}
+
+func AuthorizeURL(redirectURL, state string) string {
+	query := url.Values{}
+	query.Set("client_id", config.AppConfig.GithubOAuthID)
+	query.Set("redirect_uri", redirectURL)
+	query.Set("scope", oauthScope)
+	query.Set("state", state)
+	query.Set("allow_signup", "true")
+
+	return fmt.Sprintf("%s/login/oauth/authorize?%s", oauthBaseURL(), query.Encode())
+}
+
+func ExchangeCode(code, redirectURL string) (string, error) {
+	form := url.Values{}
+	form.Set("client_id", config.AppConfig.GithubOAuthID)
+	form.Set("client_secret", config.AppConfig.GithubOAuthSecret)
+	form.Set("code", code)
+	form.Set("redirect_uri", redirectURL)
+
+	req, err := http.NewRequest("POST", oauthBaseURL()+"/login/oauth/access_token", strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", fmt.Errorf("failed to create GitHub request: %v", err)
+	}
+
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to exchange GitHub code: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return "", fmt.Errorf("GitHub code exchange failed with status: %s, response: %s", resp.Status, body)
+	}
+
+	var result struct {
+		AccessToken      string `json:"access_token"`
+		Error            string `json:"error"`
+		ErrorDescription string `json:"error_description"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		return "", fmt.Errorf("failed to decode GitHub token response: %v", err)
+	}
+
+	if result.Error != "" {
+		return "", fmt.Errorf("GitHub code exchange failed: %s: %s", result.Error, result.ErrorDescription)
+	}
+
+	if result.AccessToken == "" {
+		return "", fmt.Errorf("GitHub code exchange returned no access token")
+	}
+
+	return result.AccessToken, nil
+}
+
+func GetAuthenticatedUser(accessToken string) (OAuthUser, error) {
+	var user OAuthUser
+	if err := getWithToken(accessToken, "/user", &user); err != nil {
+		return OAuthUser{}, err
+	}
+
+	if user.ID == 0 || user.Login == "" {
+		return OAuthUser{}, fmt.Errorf("GitHub returned an incomplete user profile")
+	}
+
+	return user, nil
+}
+
+func GetPrimaryVerifiedEmail(accessToken string) (string, error) {
+	var emails []oauthEmail
+	if err := getWithToken(accessToken, "/user/emails", &emails); err != nil {
+		return "", err
+	}
+
+	for _, e := range emails {
+		if e.Primary && e.Verified {
+			return e.Email, nil
+		}
+	}
+
+	return "", nil
+}
+
+func getWithToken(accessToken, path string, out interface{}) error {
+	req, err := http.NewRequest("GET", apiBaseURL()+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create GitHub request: %v", err)
+	}
+
+	req.Header.Set("Authorization", "Bearer "+accessToken)
+	req.Header.Set("Accept", "application/vnd.github.v3+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("failed to call GitHub API: %v", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		body, _ := io.ReadAll(resp.Body)
+		return fmt.Errorf("GitHub API %s failed with status: %s, response: %s", path, resp.Status, body)
+	}
+
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("failed to decode GitHub response: %v", err)
+	}
+
+	return nil
+}
+
+func oauthBaseURL() string {
+	if config.AppConfig.GithubOAuthURL == "" {
+		return defaultOAuthURL
+	}
+
+	return strings.TrimRight(config.AppConfig.GithubOAuthURL, "/")
+}
+
+func apiBaseURL() string {
+	if config.AppConfig.GithubAPIURL == "" {
+		return defaultAPIURL
+	}
+
+	return strings.TrimRight(config.AppConfig.GithubAPIURL, "/")
+}
@@ -0,0 +1,173 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	ReasonCrashLoopBackOff = "CrashLoopBackOff"
+	ReasonImagePullBackOff = "ImagePullBackOff"
+	ReasonErrImagePull     = "ErrImagePull"
+	ReasonOOMKilled        = "OOMKilled"
+)
+
+type Event struct {
+	Type      string    `json:"type"`
+	Reason    string    `json:"reason"`
+	Message   string    `json:"message"`
+	Kind      string    `json:"kind"`
+	Name      string    `json:"name"`
+	Count     int32     `json:"count"`
+	FirstSeen time.Time `json:"first_seen"`
+	LastSeen  time.Time `json:"last_seen"`
+}
+
+type ContainerTermination struct {
+	Pod          string    `json:"pod"`
+	Container    string    `json:"container"`
+	Reason       string    `json:"reason"`
+	Message      string    `json:"message"`
+	ExitCode     int32     `json:"exit_code"`
+	RestartCount int32     `json:"restart_count"`
+	FinishedAt   time.Time `json:"finished_at"`
+}
+
+// ContainerProblem is a container state that needs the owner's attention.
+type ContainerProblem struct {
+	Pod       string
+	Container string
+	Reason    string
+	Message   string
+}
+
+// ListEvents returns the namespace's events, most recent first.
+func ListEvents(namespaceName string) ([]Event, error) {
+	list, err := clientset.CoreV1().Events(namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list events in %s: %v", namespaceName, err)
+	}
+
+	var events []Event
+	for _, item := range list.Items {
+		lastSeen := item.LastTimestamp.Time
+		if lastSeen.IsZero() {
+			lastSeen = item.EventTime.Time
+		}
+		count := item.Count
+		if count == 0 {
+			count = 1
+		}
+
+		events = append(events, Event{
+			Type:      item.Type,
+			Reason:    item.Reason,
+			Message:   item.Message,
+			Kind:      item.InvolvedObject.Kind,
+			Name:      item.InvolvedObject.Name,
+			Count:     count,
+			FirstSeen: item.FirstTimestamp.Time,
+			LastSeen:  lastSeen,
+		})
+	}
+
+	sort.Slice(events, func(i, j int) bool {
+		return events[i].LastSeen.After(events[j].LastSeen)
+	})
+
+	return events, nil
+}
+
+// ListContainerTerminations returns the most recent termination of every
+// container that has exited, whether it is still terminated or restarted.
+func ListContainerTerminations(namespaceName string) ([]ContainerTermination, error) {
+	pods, err := clientset.CoreV1().Pods(namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in %s: %v", namespaceName, err)
+	}
+
+	var terminations []ContainerTermination
+	for _, pod := range pods.Items {
+		for _, container := range pod.Status.ContainerStatuses {
+			terminated := container.State.Terminated
+			if terminated == nil {
+				terminated = container.LastTerminationState.Terminated
+			}
+			if terminated == nil {
+				continue
+			}
+
+			terminations = append(terminations, ContainerTermination{
+				Pod:          pod.Name,
+				Container:    container.Name,
+				Reason:       terminated.Reason,
+				Message:      terminated.Message,
+				ExitCode:     terminated.ExitCode,
+				RestartCount: container.RestartCount,
+				FinishedAt:   terminated.FinishedAt.Time,
+			})
+		}
+	}
+
+	return terminations, nil
+}
+
+// FindContainerProblems reports containers that are crash looping, cannot
+// pull their image or were last killed for running out of memory.
+func FindContainerProblems(namespaceName string) ([]ContainerProblem, error) {
+	pods, err := clientset.CoreV1().Pods(namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in %s: %v", namespaceName, err)
+	}
+
+	var problems []ContainerProblem
+	for _, pod := range pods.Items {
+		if pod.DeletionTimestamp != nil {
+			continue
+		}
+
+		for _, container := range pod.Status.ContainerStatuses {
+			if problem, ok := containerProblem(container); ok {
+				problem.Pod = pod.Name
+				problems = append(problems, problem)
+			}
+		}
+	}
+
+	return problems, nil
+}
+
+func containerProblem(container corev1.ContainerStatus) (ContainerProblem, bool) {
+	problem := ContainerProblem{Container: container.Name}
+
+	if waiting := container.State.Waiting; waiting != nil {
+		switch waiting.Reason {
+		case ReasonImagePullBackOff, ReasonErrImagePull:
+			problem.Reason = ReasonImagePullBackOff
+			problem.Message = waiting.Message
+			return problem, true
+		case ReasonCrashLoopBackOff:
+			// Running out of memory is the more useful explanation of the
+			// crash loop when it is the cause.
+			if last := container.LastTerminationState.Terminated; last != nil && last.Reason == ReasonOOMKilled {
+				problem.Reason = ReasonOOMKilled
+				return problem, true
+			}
+			problem.Reason = ReasonCrashLoopBackOff
+			problem.Message = waiting.Message
+			return problem, true
+		}
+	}
+
+	if last := container.LastTerminationState.Terminated; last != nil && last.Reason == ReasonOOMKilled {
+		problem.Reason = ReasonOOMKilled
+		return problem, true
+	}
+
+	return ContainerProblem{}, false
+}
@@ -0,0 +1,110 @@
+package kubernetes
+
+import (
+	"testing"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+func TestContainerProblem(t *testing.T) {
+	oomKilled := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: ReasonOOMKilled, ExitCode: 137}}
+	errored := corev1.ContainerState{Terminated: &corev1.ContainerStateTerminated{Reason: "Error", ExitCode: 1}}
+
+	tests := []struct {
+		name       string
+		state      corev1.ContainerState
+		last       corev1.ContainerState
+		wantOK     bool
+		wantReason string
+	}{
+		{
+			name:   "running",
+			state:  corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			wantOK: false,
+		},
+		{
+			name:   "creating",
+			state:  corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: "ContainerCreating"}},
+			wantOK: false,
+		},
+		{
+			name:   "restarted after error",
+			state:  corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			last:   errored,
+			wantOK: false,
+		},
+		{
+			name:       "image pull back-off",
+			state:      corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: ReasonImagePullBackOff}},
+			wantOK:     true,
+			wantReason: ReasonImagePullBackOff,
+		},
+		{
+			name:       "image pull error",
+			state:      corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: ReasonErrImagePull}},
+			wantOK:     true,
+			wantReason: ReasonImagePullBackOff,
+		},
+		{
+			name:       "crash loop",
+			state:      corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: ReasonCrashLoopBackOff}},
+			last:       errored,
+			wantOK:     true,
+			wantReason: ReasonCrashLoopBackOff,
+		},
+		{
+			name:       "crash loop from OOM",
+			state:      corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: ReasonCrashLoopBackOff}},
+			last:       oomKilled,
+			wantOK:     true,
+			wantReason: ReasonOOMKilled,
+		},
+		{
+			name:       "restarted after OOM",
+			state:      corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			last:       oomKilled,
+			wantOK:     true,
+			wantReason: ReasonOOMKilled,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			problem, ok := containerProblem(corev1.ContainerStatus{
+				Name:                 "web",
+				State:                tt.state,
+				LastTerminationState: tt.last,
+			})
+			if ok != tt.wantOK {
+				t.Fatalf("ok = %v, want %v", ok, tt.wantOK)
+			}
+			if problem.Reason != tt.wantReason {
+				t.Errorf("reason = %q, want %q", problem.Reason, tt.wantReason)
+			}
+			if ok && problem.Container != "web" {
+				t.Errorf("container = %q, want %q", problem.Container, "web")
+			}
+		})
+	}
+}
+
+func TestFindContainerProblemsSkipsTerminatingPods(t *testing.T) {
+	crashing := waitingPod("web-1", ReasonCrashLoopBackOff)
+	terminating := waitingPod("web-2", ReasonCrashLoopBackOff)
+	now := metav1.Now()
+	terminating.DeletionTimestamp = &now
+	terminating.Finalizers = []string{"example.com/hold"}
+
+	SetClientset(fake.NewSimpleClientset(crashing, terminating))
+
+	problems, err := FindContainerProblems(testNamespace)
+	if err != nil {
+		t.Fatalf("FindContainerProblems returned error: %v", err)
+	}
+
+	if len(problems) != 1 || problems[0].Pod != "web-1" {
+		t.Errorf("problems = %+v, want one for web-1", problems)
+	}
+}
@@ -4,16 +4,25 @@ import (
 	"context"
 	"fmt"
 	"log"
+	"strconv"
+	"time"
 
 	"github.com/injunweb/backend-server/internal/config"
 
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/clientcmd"
 )
 
-var clientset *kubernetes.Clientset
+var clientset kubernetes.Interface
+
+// SetClientset replaces the client used by this package, for example with a
+// fake clientset from k8s.io/client-go/kubernetes/fake.
+func SetClientset(client kubernetes.Interface) {
+	clientset = client
+}
 
 func Init() error {
 	var restConfig *rest.Config
@@ -47,6 +56,20 @@ func NamespaceExists(namespaceName string) bool {
 	return true
 }
 
+// CheckNamespace is like NamespaceExists but tells a missing namespace apart
+// from a failed lookup.
+func CheckNamespace(namespaceName string) (bool, error) {
+	_, err := clientset.CoreV1().Namespaces().Get(context.TODO(), namespaceName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get namespace %s: %v", namespaceName, err)
+	}
+
+	return true, nil
+}
+
 func DeleteNamespace(namespaceName string) error {
 	err := clientset.CoreV1().Namespaces().Delete(context.TODO(), namespaceName, metav1.DeleteOptions{})
 	if err != nil {
@@ -56,3 +79,176 @@ func DeleteNamespace(namespaceName string) error {
 	log.Printf("Namespace %s deleted successfully\n", namespaceName)
 	return nil
 }
+
+const (
+	suspendedReplicasAnnotation = "injunweb.com/suspended-replicas"
+	restartedAtAnnotation       = "kubectl.kubernetes.io/restartedAt"
+)
+
+// RestartDeployments triggers a rolling restart of every deployment in the
+// namespace the same way kubectl rollout restart does.
+func RestartDeployments(namespaceName string) error {
+	deployments, err := clientset.AppsV1().Deployments(namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list deployments in %s: %v", namespaceName, err)
+	}
+
+	if len(deployments.Items) == 0 {
+		return fmt.Errorf("no deployments found in %s", namespaceName)
+	}
+
+	for _, deployment := range deployments.Items {
+		if deployment.Spec.Template.Annotations == nil {
+			deployment.Spec.Template.Annotations = map[string]string{}
+		}
+		deployment.Spec.Template.Annotations[restartedAtAnnotation] = time.Now().Format(time.RFC3339)
+
+		if _, err := clientset.AppsV1().Deployments(namespaceName).Update(context.TODO(), &deployment, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to restart deployment %s/%s: %v", namespaceName, deployment.Name, err)
+		}
+	}
+
+	log.Printf("Namespace %s restarted\n", namespaceName)
+	return nil
+}
+
+// ScaleDeployments sets the replica count of every deployment in the namespace.
+func ScaleDeployments(namespaceName string, replicas int32) error {
+	deployments, err := clientset.AppsV1().Deployments(namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list deployments in %s: %v", namespaceName, err)
+	}
+
+	for _, deployment := range deployments.Items {
+		if deployment.Spec.Replicas != nil && *deployment.Spec.Replicas == replicas {
+			continue
+		}
+
+		deployment.Spec.Replicas = &replicas
+		if _, err := clientset.AppsV1().Deployments(namespaceName).Update(context.TODO(), &deployment, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to scale deployment %s/%s: %v", namespaceName, deployment.Name, err)
+		}
+	}
+
+	log.Printf("Namespace %s scaled to %d replicas\n", namespaceName, replicas)
+	return nil
+}
+
+// ScaleDownNamespace scales every deployment and statefulset in the namespace to
+// zero, remembering the previous replica count in an annotation.
+func ScaleDownNamespace(namespaceName string) error {
+	deployments, err := clientset.AppsV1().Deployments(namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list deployments in %s: %v", namespaceName, err)
+	}
+
+	for _, deployment := range deployments.Items {
+		if deployment.Spec.Replicas != nil && *deployment.Spec.Replicas == 0 {
+			continue
+		}
+
+		replicas := int32(1)
+		if deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+
+		if deployment.Annotations == nil {
+			deployment.Annotations = map[string]string{}
+		}
+		deployment.Annotations[suspendedReplicasAnnotation] = strconv.Itoa(int(replicas))
+		zero := int32(0)
+		deployment.Spec.Replicas = &zero
+
+		if _, err := clientset.AppsV1().Deployments(namespaceName).Update(context.TODO(), &deployment, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to scale down deployment %s/%s: %v", namespaceName, deployment.Name, err)
+		}
+	}
+
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list statefulsets in %s: %v", namespaceName, err)
+	}
+
+	for _, statefulSet := range statefulSets.Items {
+		if statefulSet.Spec.Replicas != nil && *statefulSet.Spec.Replicas == 0 {
+			continue
+		}
+
+		replicas := int32(1)
+		if statefulSet.Spec.Replicas != nil {
+			replicas = *statefulSet.Spec.Replicas
+		}
+
+		if statefulSet.Annotations == nil {
+			statefulSet.Annotations = map[string]string{}
+		}
+		statefulSet.Annotations[suspendedReplicasAnnotation] = strconv.Itoa(int(replicas))
+		zero := int32(0)
+		statefulSet.Spec.Replicas = &zero
+
+		if _, err := clientset.AppsV1().StatefulSets(namespaceName).Update(context.TODO(), &statefulSet, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to scale down statefulset %s/%s: %v", namespaceName, statefulSet.Name, err)
+		}
+	}
+
+	log.Printf("Namespace %s scaled down\n", namespaceName)
+	return nil
+}
+
+// RestoreNamespace reverts ScaleDownNamespace using the recorded replica counts.
+func RestoreNamespace(namespaceName string) error {
+	deployments, err := clientset.AppsV1().Deployments(namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list deployments in %s: %v", namespaceName, err)
+	}
+
+	for _, deployment := range deployments.Items {
+		replicas, ok := suspendedReplicas(deployment.Annotations)
+		if !ok {
+			continue
+		}
+
+		delete(deployment.Annotations, suspendedReplicasAnnotation)
+		deployment.Spec.Replicas = &replicas
+
+		if _, err := clientset.AppsV1().Deployments(namespaceName).Update(context.TODO(), &deployment, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to restore deployment %s/%s: %v", namespaceName, deployment.Name, err)
+		}
+	}
+
+	statefulSets, err := clientset.AppsV1().StatefulSets(namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return fmt.Errorf("failed to list statefulsets in %s: %v", namespaceName, err)
+	}
+
+	for _, statefulSet := range statefulSets.Items {
+		replicas, ok := suspendedReplicas(statefulSet.Annotations)
+		if !ok {
+			continue
+		}
+
+		delete(statefulSet.Annotations, suspendedReplicasAnnotation)
+		statefulSet.Spec.Replicas = &replicas
+
+		if _, err := clientset.AppsV1().StatefulSets(namespaceName).Update(context.TODO(), &statefulSet, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to restore statefulset %s/%s: %v", namespaceName, statefulSet.Name, err)
+		}
+	}
+
+	log.Printf("Namespace %s restored\n", namespaceName)
+	return nil
+}
+
+func suspendedReplicas(annotations map[string]string) (int32, bool) {
+	value, ok := annotations[suspendedReplicasAnnotation]
+	if !ok {
+		return 0, false
+	}
+
+	replicas, err := strconv.Atoi(value)
+	if err != nil || replicas < 0 {
+		return 1, true
+	}
+
+	return int32(replicas), true
+}
@@ -0,0 +1,55 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+type LogOptions struct {
+	Container string
+	Follow    bool
+	TailLines *int64
+	SinceTime *time.Time
+	Previous  bool
+}
+
+func ListPodNames(namespaceName string) ([]string, error) {
+	pods, err := clientset.CoreV1().Pods(namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to list pods in %s: %v", namespaceName, err)
+	}
+
+	var names []string
+	for _, pod := range pods.Items {
+		names = append(names, pod.Name)
+	}
+
+	return names, nil
+}
+
+// StreamPodLogs opens a log stream for a pod. The stream ends when ctx is
+// cancelled or, without Follow, once the requested lines have been read.
+func StreamPodLogs(ctx context.Context, namespaceName string, podName string, options LogOptions) (io.ReadCloser, error) {
+	podLogOptions := &corev1.PodLogOptions{
+		Container: options.Container,
+		Follow:    options.Follow,
+		TailLines: options.TailLines,
+		Previous:  options.Previous,
+	}
+	if options.SinceTime != nil {
+		sinceTime := metav1.NewTime(*options.SinceTime)
+		podLogOptions.SinceTime = &sinceTime
+	}
+
+	stream, err := clientset.CoreV1().Pods(namespaceName).GetLogs(podName, podLogOptions).Stream(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stream logs of %s/%s: %v", namespaceName, podName, err)
+	}
+
+	return stream, nil
+}
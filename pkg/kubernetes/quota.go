@@ -0,0 +1,204 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"log"
+
+	corev1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	"k8s.io/apimachinery/pkg/api/resource"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	resourceQuotaName = "injunweb-quota"
+	limitRangeName    = "injunweb-limits"
+)
+
+type QuotaSpec struct {
+	CPU                  string
+	Memory               string
+	Pods                 int
+	Storage              string
+	DefaultCPU           string
+	DefaultMemory        string
+	DefaultCPURequest    string
+	DefaultMemoryRequest string
+}
+
+// FitsDefaultPods reports whether the quota has room for n pods that run on
+// the default limits.
+func (q QuotaSpec) FitsDefaultPods(n int) (bool, error) {
+	if n > q.Pods {
+		return false, nil
+	}
+
+	for _, pair := range [][2]string{{q.CPU, q.DefaultCPU}, {q.Memory, q.DefaultMemory}} {
+		total, err := resource.ParseQuantity(pair[0])
+		if err != nil {
+			return false, fmt.Errorf("invalid quantity %q: %v", pair[0], err)
+		}
+		perPod, err := resource.ParseQuantity(pair[1])
+		if err != nil {
+			return false, fmt.Errorf("invalid quantity %q: %v", pair[1], err)
+		}
+		if perPod.MilliValue()*int64(n) > total.MilliValue() {
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+type QuotaUsage struct {
+	Hard map[string]string `json:"hard"`
+	Used map[string]string `json:"used"`
+}
+
+// EnsureNamespace creates the namespace if it does not exist yet, so quotas
+// are in place before the first workload is deployed into it.
+func EnsureNamespace(namespaceName string) error {
+	exists, err := CheckNamespace(namespaceName)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return nil
+	}
+
+	namespace := &corev1.Namespace{ObjectMeta: metav1.ObjectMeta{Name: namespaceName}}
+	if _, err := clientset.CoreV1().Namespaces().Create(context.TODO(), namespace, metav1.CreateOptions{}); err != nil && !apierrors.IsAlreadyExists(err) {
+		return fmt.Errorf("failed to create namespace %s: %v", namespaceName, err)
+	}
+
+	return nil
+}
+
+// QuotaExists reports whether the namespace has the platform's ResourceQuota.
+// A missing namespace has none.
+func QuotaExists(namespaceName string) (bool, error) {
+	_, err := clientset.CoreV1().ResourceQuotas(namespaceName).Get(context.TODO(), resourceQuotaName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to get resource quota in %s: %v", namespaceName, err)
+	}
+
+	return true, nil
+}
+
+// ApplyQuota creates or updates the namespace's ResourceQuota and LimitRange.
+func ApplyQuota(namespaceName string, spec QuotaSpec) error {
+	hard, err := parseResourceList(map[corev1.ResourceName]string{
+		corev1.ResourceRequestsCPU:     spec.CPU,
+		corev1.ResourceLimitsCPU:       spec.CPU,
+		corev1.ResourceRequestsMemory:  spec.Memory,
+		corev1.ResourceLimitsMemory:    spec.Memory,
+		corev1.ResourceRequestsStorage: spec.Storage,
+		corev1.ResourcePods:            fmt.Sprintf("%d", spec.Pods),
+	})
+	if err != nil {
+		return err
+	}
+
+	defaults, err := parseResourceList(map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    spec.DefaultCPU,
+		corev1.ResourceMemory: spec.DefaultMemory,
+	})
+	if err != nil {
+		return err
+	}
+
+	defaultRequests, err := parseResourceList(map[corev1.ResourceName]string{
+		corev1.ResourceCPU:    spec.DefaultCPURequest,
+		corev1.ResourceMemory: spec.DefaultMemoryRequest,
+	})
+	if err != nil {
+		return err
+	}
+
+	quotas := clientset.CoreV1().ResourceQuotas(namespaceName)
+	quota, err := quotas.Get(context.TODO(), resourceQuotaName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		quota = &corev1.ResourceQuota{
+			ObjectMeta: metav1.ObjectMeta{Name: resourceQuotaName, Namespace: namespaceName},
+			Spec:       corev1.ResourceQuotaSpec{Hard: hard},
+		}
+		if _, err := quotas.Create(context.TODO(), quota, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create resource quota in %s: %v", namespaceName, err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to get resource quota in %s: %v", namespaceName, err)
+	default:
+		quota.Spec.Hard = hard
+		if _, err := quotas.Update(context.TODO(), quota, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update resource quota in %s: %v", namespaceName, err)
+		}
+	}
+
+	limitRanges := clientset.CoreV1().LimitRanges(namespaceName)
+	limits := []corev1.LimitRangeItem{{
+		Type:           corev1.LimitTypeContainer,
+		Default:        defaults,
+		DefaultRequest: defaultRequests,
+	}}
+	limitRange, err := limitRanges.Get(context.TODO(), limitRangeName, metav1.GetOptions{})
+	switch {
+	case apierrors.IsNotFound(err):
+		limitRange = &corev1.LimitRange{
+			ObjectMeta: metav1.ObjectMeta{Name: limitRangeName, Namespace: namespaceName},
+			Spec:       corev1.LimitRangeSpec{Limits: limits},
+		}
+		if _, err := limitRanges.Create(context.TODO(), limitRange, metav1.CreateOptions{}); err != nil {
+			return fmt.Errorf("failed to create limit range in %s: %v", namespaceName, err)
+		}
+	case err != nil:
+		return fmt.Errorf("failed to get limit range in %s: %v", namespaceName, err)
+	default:
+		limitRange.Spec.Limits = limits
+		if _, err := limitRanges.Update(context.TODO(), limitRange, metav1.UpdateOptions{}); err != nil {
+			return fmt.Errorf("failed to update limit range in %s: %v", namespaceName, err)
+		}
+	}
+
+	log.Printf("Quota applied to namespace %s\n", namespaceName)
+	return nil
+}
+
+// GetQuotaUsage reports the limits and current usage tracked by the
+// namespace's ResourceQuota. A namespace without one yields empty maps.
+func GetQuotaUsage(namespaceName string) (QuotaUsage, error) {
+	usage := QuotaUsage{Hard: map[string]string{}, Used: map[string]string{}}
+
+	quota, err := clientset.CoreV1().ResourceQuotas(namespaceName).Get(context.TODO(), resourceQuotaName, metav1.GetOptions{})
+	if apierrors.IsNotFound(err) {
+		return usage, nil
+	}
+	if err != nil {
+		return QuotaUsage{}, fmt.Errorf("failed to get resource quota in %s: %v", namespaceName, err)
+	}
+
+	for name, quantity := range quota.Status.Hard {
+		usage.Hard[string(name)] = quantity.String()
+	}
+	for name, quantity := range quota.Status.Used {
+		usage.Used[string(name)] = quantity.String()
+	}
+
+	return usage, nil
+}
+
+func parseResourceList(values map[corev1.ResourceName]string) (corev1.ResourceList, error) {
+	list := corev1.ResourceList{}
+	for name, value := range values {
+		quantity, err := resource.ParseQuantity(value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid quantity %q for %s: %v", value, name, err)
+		}
+		list[name] = quantity
+	}
+	return list, nil
+}
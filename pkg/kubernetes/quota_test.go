@@ -0,0 +1,42 @@
+package kubernetes
+
+import "testing"
+
+func TestQuotaSpecFitsDefaultPods(t *testing.T) {
+	spec := QuotaSpec{CPU: "1", Memory: "1Gi", Pods: 5, DefaultCPU: "250m", DefaultMemory: "256Mi"}
+
+	tests := []struct {
+		name string
+		pods int
+		want bool
+	}{
+		{"one pod", 1, true},
+		{"exactly full", 4, true},
+		{"over cpu and memory", 5, false},
+		{"over pod count", 6, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := spec.FitsDefaultPods(tt.pods)
+			if err != nil {
+				t.Fatalf("FitsDefaultPods returned error: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("FitsDefaultPods(%d) = %v, want %v", tt.pods, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestQuotaSpecFitsDefaultPodsMemoryBound(t *testing.T) {
+	spec := QuotaSpec{CPU: "4", Memory: "1Gi", Pods: 10, DefaultCPU: "100m", DefaultMemory: "512Mi"}
+
+	got, err := spec.FitsDefaultPods(3)
+	if err != nil {
+		t.Fatalf("FitsDefaultPods returned error: %v", err)
+	}
+	if got {
+		t.Error("FitsDefaultPods(3) = true, want false when memory runs out")
+	}
+}
@@ -0,0 +1,245 @@
+package kubernetes
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	HealthHealthy     = "healthy"
+	HealthProgressing = "progressing"
+	HealthDegraded    = "degraded"
+	HealthDown        = "down"
+	HealthStopped     = "stopped"
+	HealthNotDeployed = "not_deployed"
+)
+
+// Container states that will not resolve without a change to the
+// application or its image.
+var failingWaitingReasons = map[string]bool{
+	"CrashLoopBackOff":           true,
+	"ImagePullBackOff":           true,
+	"ErrImagePull":               true,
+	"InvalidImageName":           true,
+	"CreateContainerConfigError": true,
+	"CreateContainerError":       true,
+}
+
+type DeploymentStatus struct {
+	Name              string   `json:"name"`
+	Replicas          int32    `json:"replicas"`
+	ReadyReplicas     int32    `json:"ready_replicas"`
+	UpdatedReplicas   int32    `json:"updated_replicas"`
+	AvailableReplicas int32    `json:"available_replicas"`
+	Images            []string `json:"images"`
+}
+
+type ReplicaSetStatus struct {
+	Name          string   `json:"name"`
+	Deployment    string   `json:"deployment"`
+	Revision      string   `json:"revision"`
+	Replicas      int32    `json:"replicas"`
+	ReadyReplicas int32    `json:"ready_replicas"`
+	Images        []string `json:"images"`
+}
+
+type ContainerStatus struct {
+	Name         string `json:"name"`
+	Image        string `json:"image"`
+	Tag          string `json:"tag"`
+	Ready        bool   `json:"ready"`
+	RestartCount int32  `json:"restart_count"`
+	State        string `json:"state"`
+	Reason       string `json:"reason"`
+}
+
+type PodStatus struct {
+	Name       string            `json:"name"`
+	Phase      string            `json:"phase"`
+	Ready      bool              `json:"ready"`
+	Restarts   int32             `json:"restarts"`
+	Node       string            `json:"node"`
+	Containers []ContainerStatus `json:"containers"`
+}
+
+type NamespaceStatus struct {
+	Deployments []DeploymentStatus `json:"deployments"`
+	ReplicaSets []ReplicaSetStatus `json:"replica_sets"`
+	Pods        []PodStatus        `json:"pods"`
+}
+
+// GetNamespaceStatus collects the state of the workloads in a namespace. A
+// missing namespace yields an empty status.
+func GetNamespaceStatus(namespaceName string) (NamespaceStatus, error) {
+	var status NamespaceStatus
+
+	deployments, err := clientset.AppsV1().Deployments(namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return NamespaceStatus{}, fmt.Errorf("failed to list deployments in %s: %v", namespaceName, err)
+	}
+
+	for _, deployment := range deployments.Items {
+		replicas := int32(1)
+		if deployment.Spec.Replicas != nil {
+			replicas = *deployment.Spec.Replicas
+		}
+
+		status.Deployments = append(status.Deployments, DeploymentStatus{
+			Name:              deployment.Name,
+			Replicas:          replicas,
+			ReadyReplicas:     deployment.Status.ReadyReplicas,
+			UpdatedReplicas:   deployment.Status.UpdatedReplicas,
+			AvailableReplicas: deployment.Status.AvailableReplicas,
+			Images:            containerImages(deployment.Spec.Template.Spec.Containers),
+		})
+	}
+
+	replicaSets, err := clientset.AppsV1().ReplicaSets(namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return NamespaceStatus{}, fmt.Errorf("failed to list replicasets in %s: %v", namespaceName, err)
+	}
+
+	for _, replicaSet := range replicaSets.Items {
+		// Old revisions are kept around at zero replicas for rollbacks.
+		if replicaSet.Status.Replicas == 0 && (replicaSet.Spec.Replicas == nil || *replicaSet.Spec.Replicas == 0) {
+			continue
+		}
+
+		var owner string
+		for _, ref := range replicaSet.OwnerReferences {
+			if ref.Kind == "Deployment" {
+				owner = ref.Name
+			}
+		}
+
+		status.ReplicaSets = append(status.ReplicaSets, ReplicaSetStatus{
+			Name:          replicaSet.Name,
+			Deployment:    owner,
+			Revision:      replicaSet.Annotations["deployment.kubernetes.io/revision"],
+			Replicas:      replicaSet.Status.Replicas,
+			ReadyReplicas: replicaSet.Status.ReadyReplicas,
+			Images:        containerImages(replicaSet.Spec.Template.Spec.Containers),
+		})
+	}
+
+	pods, err := clientset.CoreV1().Pods(namespaceName).List(context.TODO(), metav1.ListOptions{})
+	if err != nil {
+		return NamespaceStatus{}, fmt.Errorf("failed to list pods in %s: %v", namespaceName, err)
+	}
+
+	for _, pod := range pods.Items {
+		podStatus := PodStatus{
+			Name:  pod.Name,
+			Phase: string(pod.Status.Phase),
+			Node:  pod.Spec.NodeName,
+		}
+
+		for _, condition := range pod.Status.Conditions {
+			if condition.Type == corev1.PodReady {
+				podStatus.Ready = condition.Status == corev1.ConditionTrue
+			}
+		}
+
+		for _, container := range pod.Status.ContainerStatuses {
+			state, reason := containerState(container.State)
+			podStatus.Restarts += container.RestartCount
+			podStatus.Containers = append(podStatus.Containers, ContainerStatus{
+				Name:         container.Name,
+				Image:        container.Image,
+				Tag:          ImageTag(container.Image),
+				Ready:        container.Ready,
+				RestartCount: container.RestartCount,
+				State:        state,
+				Reason:       reason,
+			})
+		}
+
+		status.Pods = append(status.Pods, podStatus)
+	}
+
+	return status, nil
+}
+
+// Health condenses the namespace status into a single verdict.
+func (s NamespaceStatus) Health() string {
+	if len(s.Deployments) == 0 {
+		return HealthNotDeployed
+	}
+
+	var desired, ready, updated int32
+	for _, deployment := range s.Deployments {
+		desired += deployment.Replicas
+		ready += deployment.ReadyReplicas
+		updated += deployment.UpdatedReplicas
+	}
+
+	if desired == 0 {
+		return HealthStopped
+	}
+
+	failing := false
+	for _, pod := range s.Pods {
+		if pod.Phase == string(corev1.PodFailed) {
+			failing = true
+		}
+		for _, container := range pod.Containers {
+			if failingWaitingReasons[container.Reason] {
+				failing = true
+			}
+		}
+	}
+
+	switch {
+	case failing && ready == 0:
+		return HealthDown
+	case failing:
+		return HealthDegraded
+	case ready < desired || updated < desired:
+		return HealthProgressing
+	default:
+		return HealthHealthy
+	}
+}
+
+// ImageTag returns the tag or digest of an image reference, or "latest" when
+// none is given.
+func ImageTag(image string) string {
+	if i := strings.Index(image, "@"); i >= 0 {
+		return image[i+1:]
+	}
+
+	name := image
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	if i := strings.LastIndex(name, ":"); i >= 0 {
+		return name[i+1:]
+	}
+
+	return "latest"
+}
+
+func containerImages(containers []corev1.Container) []string {
+	var images []string
+	for _, container := range containers {
+		images = append(images, container.Image)
+	}
+	return images
+}
+
+func containerState(state corev1.ContainerState) (string, string) {
+	switch {
+	case state.Running != nil:
+		return "running", ""
+	case state.Waiting != nil:
+		return "waiting", state.Waiting.Reason
+	case state.Terminated != nil:
+		return "terminated", state.Terminated.Reason
+	default:
+		return "unknown", ""
+	}
+}
@@ -0,0 +1,164 @@
+package kubernetes
+
+import (
+	"testing"
+
+	appsv1 "k8s.io/api/apps/v1"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/client-go/kubernetes/fake"
+)
+
+const testNamespace = "demo"
+
+func testDeployment(replicas, ready, updated int32) *appsv1.Deployment {
+	return &appsv1.Deployment{
+		ObjectMeta: metav1.ObjectMeta{Name: "web", Namespace: testNamespace},
+		Spec: appsv1.DeploymentSpec{
+			Replicas: &replicas,
+			Template: corev1.PodTemplateSpec{
+				Spec: corev1.PodSpec{
+					Containers: []corev1.Container{{Name: "web", Image: "harbor.example.com/demo/web:abc123"}},
+				},
+			},
+		},
+		Status: appsv1.DeploymentStatus{
+			ReadyReplicas:     ready,
+			UpdatedReplicas:   updated,
+			AvailableReplicas: ready,
+		},
+	}
+}
+
+func runningPod(name string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionTrue}},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:  "web",
+				Image: "harbor.example.com/demo/web:abc123",
+				Ready: true,
+				State: corev1.ContainerState{Running: &corev1.ContainerStateRunning{}},
+			}},
+		},
+	}
+}
+
+func waitingPod(name string, reason string) *corev1.Pod {
+	return &corev1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: name, Namespace: testNamespace},
+		Status: corev1.PodStatus{
+			Phase:      corev1.PodRunning,
+			Conditions: []corev1.PodCondition{{Type: corev1.PodReady, Status: corev1.ConditionFalse}},
+			ContainerStatuses: []corev1.ContainerStatus{{
+				Name:         "web",
+				Image:        "harbor.example.com/demo/web:abc123",
+				RestartCount: 5,
+				State:        corev1.ContainerState{Waiting: &corev1.ContainerStateWaiting{Reason: reason}},
+			}},
+		},
+	}
+}
+
+func TestGetNamespaceStatusHealth(t *testing.T) {
+	tests := []struct {
+		name    string
+		objects []runtime.Object
+		want    string
+	}{
+		{
+			name:    "not deployed",
+			objects: nil,
+			want:    HealthNotDeployed,
+		},
+		{
+			name:    "healthy",
+			objects: []runtime.Object{testDeployment(2, 2, 2), runningPod("web-1"), runningPod("web-2")},
+			want:    HealthHealthy,
+		},
+		{
+			name:    "progressing rollout",
+			objects: []runtime.Object{testDeployment(2, 2, 1), runningPod("web-1"), runningPod("web-2")},
+			want:    HealthProgressing,
+		},
+		{
+			name:    "progressing startup",
+			objects: []runtime.Object{testDeployment(2, 1, 2), runningPod("web-1"), waitingPod("web-2", "ContainerCreating")},
+			want:    HealthProgressing,
+		},
+		{
+			name:    "degraded crash loop",
+			objects: []runtime.Object{testDeployment(2, 1, 2), runningPod("web-1"), waitingPod("web-2", "CrashLoopBackOff")},
+			want:    HealthDegraded,
+		},
+		{
+			name:    "down",
+			objects: []runtime.Object{testDeployment(1, 0, 1), waitingPod("web-1", "ImagePullBackOff")},
+			want:    HealthDown,
+		},
+		{
+			name:    "stopped",
+			objects: []runtime.Object{testDeployment(0, 0, 0)},
+			want:    HealthStopped,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			SetClientset(fake.NewSimpleClientset(tt.objects...))
+
+			status, err := GetNamespaceStatus(testNamespace)
+			if err != nil {
+				t.Fatalf("GetNamespaceStatus returned error: %v", err)
+			}
+
+			if got := status.Health(); got != tt.want {
+				t.Errorf("Health() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetNamespaceStatusContainers(t *testing.T) {
+	SetClientset(fake.NewSimpleClientset(testDeployment(1, 0, 1), waitingPod("web-1", "CrashLoopBackOff")))
+
+	status, err := GetNamespaceStatus(testNamespace)
+	if err != nil {
+		t.Fatalf("GetNamespaceStatus returned error: %v", err)
+	}
+
+	if len(status.Pods) != 1 || len(status.Pods[0].Containers) != 1 {
+		t.Fatalf("got %d pods, want 1 pod with 1 container", len(status.Pods))
+	}
+
+	pod := status.Pods[0]
+	container := pod.Containers[0]
+	if pod.Ready || pod.Restarts != 5 {
+		t.Errorf("pod ready = %v, restarts = %d; want false, 5", pod.Ready, pod.Restarts)
+	}
+	if container.State != "waiting" || container.Reason != "CrashLoopBackOff" || container.Tag != "abc123" {
+		t.Errorf("container = %+v, want waiting CrashLoopBackOff with tag abc123", container)
+	}
+}
+
+func TestImageTag(t *testing.T) {
+	tests := []struct {
+		image string
+		want  string
+	}{
+		{"nginx", "latest"},
+		{"nginx:1.27", "1.27"},
+		{"harbor.example.com:5000/demo/web", "latest"},
+		{"harbor.example.com:5000/demo/web:abc123", "abc123"},
+		{"harbor.example.com/demo/web@sha256:deadbeef", "sha256:deadbeef"},
+	}
+
+	for _, tt := range tests {
+		if got := ImageTag(tt.image); got != tt.want {
+			t.Errorf("ImageTag(%q) = %q, want %q", tt.image, got, tt.want)
+		}
+	}
+}
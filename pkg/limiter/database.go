@@ -0,0 +1,88 @@
+package limiter
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/injunweb/backend-server/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type DatabaseStore struct {
+	db *gorm.DB
+}
+
+func NewDatabaseStore(db *gorm.DB) *DatabaseStore {
+	return &DatabaseStore{db: db}
+}
+
+func (s *DatabaseStore) Load(key string) (Attempt, error) {
+	var record models.LoginAttempt
+	err := s.db.Where("`key` = ?", key).First(&record).Error
+	if err == gorm.ErrRecordNotFound {
+		return Attempt{}, nil
+	}
+	if err != nil {
+		return Attempt{}, fmt.Errorf("failed to load login attempts: %v", err)
+	}
+
+	return toAttempt(record), nil
+}
+
+// Update seeds the row outside the transaction so that concurrent callers
+// serialize on its row lock instead of racing to insert it.
+func (s *DatabaseStore) Update(key string, fn func(Attempt) Attempt) (Attempt, error) {
+	seed := models.LoginAttempt{Key: key, LastFailureAt: time.Now()}
+	if err := s.db.Clauses(clause.OnConflict{DoNothing: true}).Create(&seed).Error; err != nil {
+		return Attempt{}, fmt.Errorf("failed to save login attempts: %v", err)
+	}
+
+	var attempt Attempt
+	err := s.db.Transaction(func(tx *gorm.DB) error {
+		var record models.LoginAttempt
+		if err := tx.Clauses(clause.Locking{Strength: "UPDATE"}).Where("`key` = ?", key).First(&record).Error; err != nil {
+			return err
+		}
+
+		attempt = fn(toAttempt(record))
+
+		var lockedUntil *time.Time
+		if !attempt.LockedUntil.IsZero() {
+			value := attempt.LockedUntil
+			lockedUntil = &value
+		}
+
+		return tx.Model(&record).Updates(map[string]interface{}{
+			"failures":        attempt.Failures,
+			"last_failure_at": attempt.LastFailureAt,
+			"locked_until":    lockedUntil,
+		}).Error
+	})
+	if err != nil {
+		return Attempt{}, fmt.Errorf("failed to save login attempts: %v", err)
+	}
+
+	return attempt, nil
+}
+
+func (s *DatabaseStore) Delete(key string) error {
+	if err := s.db.Where("`key` = ?", key).Delete(&models.LoginAttempt{}).Error; err != nil {
+		return fmt.Errorf("failed to delete login attempts: %v", err)
+	}
+
+	return nil
+}
+
+func toAttempt(record models.LoginAttempt) Attempt {
+	attempt := Attempt{
+		Failures:      record.Failures,
+		LastFailureAt: record.LastFailureAt,
+	}
+	if record.LockedUntil != nil {
+		attempt.LockedUntil = *record.LockedUntil
+	}
+
+	return attempt
+}
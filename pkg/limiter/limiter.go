@@ -0,0 +1,95 @@
+package limiter
+
+import (
+	"math"
+	"time"
+)
+
+type Attempt struct {
+	Failures      int
+	LastFailureAt time.Time
+	LockedUntil   time.Time
+}
+
+// Store persists attempts per key. Update must apply fn to the current attempt
+// and store the result atomically, so concurrent failures are never lost.
+type Store interface {
+	Load(key string) (Attempt, error)
+	Update(key string, fn func(Attempt) Attempt) (Attempt, error)
+	Delete(key string) error
+}
+
+// Policy locks a key once Threshold failures happen within Window. Each further
+// failure doubles the lockout, starting at BaseLockout and capped at MaxLockout.
+type Policy struct {
+	Threshold   int
+	Window      time.Duration
+	BaseLockout time.Duration
+	MaxLockout  time.Duration
+}
+
+type Limiter struct {
+	store  Store
+	policy Policy
+}
+
+func New(store Store, policy Policy) *Limiter {
+	return &Limiter{store: store, policy: policy}
+}
+
+// Check returns how long the key remains locked, or zero if it may proceed.
+func (l *Limiter) Check(key string, now time.Time) (time.Duration, error) {
+	attempt, err := l.store.Load(key)
+	if err != nil {
+		return 0, err
+	}
+
+	if now.Before(attempt.LockedUntil) {
+		return attempt.LockedUntil.Sub(now), nil
+	}
+
+	return 0, nil
+}
+
+// Fail records a failed attempt and reports whether this failure newly locked the key.
+func (l *Limiter) Fail(key string, now time.Time) (Attempt, bool, error) {
+	wasLocked := false
+	attempt, err := l.store.Update(key, func(attempt Attempt) Attempt {
+		if !attempt.LastFailureAt.IsZero() && now.Sub(attempt.LastFailureAt) > l.policy.Window && !now.Before(attempt.LockedUntil) {
+			attempt = Attempt{}
+		}
+
+		wasLocked = now.Before(attempt.LockedUntil)
+		attempt.Failures++
+		attempt.LastFailureAt = now
+		if attempt.Failures >= l.policy.Threshold {
+			attempt.LockedUntil = now.Add(l.lockoutFor(attempt.Failures))
+		}
+
+		return attempt
+	})
+	if err != nil {
+		return Attempt{}, false, err
+	}
+
+	locked := attempt.Failures >= l.policy.Threshold && !wasLocked
+	return attempt, locked, nil
+}
+
+func (l *Limiter) Reset(key string) error {
+	return l.store.Delete(key)
+}
+
+func (l *Limiter) lockoutFor(failures int) time.Duration {
+	exponent := failures - l.policy.Threshold
+	if exponent > 30 {
+		return l.policy.MaxLockout
+	}
+
+	lockout := time.Duration(float64(l.policy.BaseLockout) * math.Pow(2, float64(exponent)))
+	if lockout > l.policy.MaxLockout || lockout <= 0 {
+		return l.policy.MaxLockout
+	}
+
+	return lockout
+}
@@ -0,0 +1,124 @@
+package limiter
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+var testPolicy = Policy{
+	Threshold:   3,
+	Window:      time.Hour,
+	BaseLockout: time.Minute,
+	MaxLockout:  10 * time.Minute,
+}
+
+func TestFailLocksAtThreshold(t *testing.T) {
+	l := New(NewMemoryStore(time.Hour), testPolicy)
+	now := time.Now()
+
+	tests := []struct {
+		failures int
+		locked   bool
+		lockout  time.Duration
+	}{
+		{failures: 1, locked: false, lockout: 0},
+		{failures: 2, locked: false, lockout: 0},
+		{failures: 3, locked: true, lockout: time.Minute},
+		{failures: 4, locked: false, lockout: 2 * time.Minute},
+		{failures: 5, locked: false, lockout: 4 * time.Minute},
+		{failures: 6, locked: false, lockout: 8 * time.Minute},
+		{failures: 7, locked: false, lockout: 10 * time.Minute},
+	}
+
+	for _, tt := range tests {
+		attempt, locked, err := l.Fail("user:alice", now)
+		if err != nil {
+			t.Fatalf("Fail returned error: %v", err)
+		}
+		if attempt.Failures != tt.failures {
+			t.Errorf("failures = %d, want %d", attempt.Failures, tt.failures)
+		}
+		if locked != tt.locked {
+			t.Errorf("after %d failures locked = %v, want %v", tt.failures, locked, tt.locked)
+		}
+
+		remaining, err := l.Check("user:alice", now)
+		if err != nil {
+			t.Fatalf("Check returned error: %v", err)
+		}
+		if remaining != tt.lockout {
+			t.Errorf("after %d failures lockout = %v, want %v", tt.failures, remaining, tt.lockout)
+		}
+	}
+}
+
+func TestFailResetsAfterWindow(t *testing.T) {
+	l := New(NewMemoryStore(2*time.Hour), testPolicy)
+	now := time.Now()
+
+	for i := 0; i < 2; i++ {
+		if _, _, err := l.Fail("ip:10.0.0.1", now); err != nil {
+			t.Fatalf("Fail returned error: %v", err)
+		}
+	}
+
+	attempt, locked, err := l.Fail("ip:10.0.0.1", now.Add(testPolicy.Window+time.Second))
+	if err != nil {
+		t.Fatalf("Fail returned error: %v", err)
+	}
+	if attempt.Failures != 1 || locked {
+		t.Errorf("got failures = %d, locked = %v; want 1, false", attempt.Failures, locked)
+	}
+}
+
+func TestReset(t *testing.T) {
+	l := New(NewMemoryStore(time.Hour), testPolicy)
+	now := time.Now()
+
+	for i := 0; i < testPolicy.Threshold; i++ {
+		l.Fail("user:bob", now)
+	}
+	if err := l.Reset("user:bob"); err != nil {
+		t.Fatalf("Reset returned error: %v", err)
+	}
+
+	remaining, _ := l.Check("user:bob", now)
+	if remaining != 0 {
+		t.Errorf("lockout after reset = %v, want 0", remaining)
+	}
+}
+
+func TestFailConcurrent(t *testing.T) {
+	l := New(NewMemoryStore(time.Hour), testPolicy)
+	now := time.Now()
+
+	const workers = 50
+	var wg sync.WaitGroup
+	var mu sync.Mutex
+	newlyLocked := 0
+	for i := 0; i < workers; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_, locked, err := l.Fail("user:carol", now)
+			if err != nil {
+				t.Errorf("Fail returned error: %v", err)
+			}
+			if locked {
+				mu.Lock()
+				newlyLocked++
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	attempt, _ := l.store.Load("user:carol")
+	if attempt.Failures != workers {
+		t.Errorf("failures = %d, want %d", attempt.Failures, workers)
+	}
+	if newlyLocked != 1 {
+		t.Errorf("newly locked %d times, want 1", newlyLocked)
+	}
+}
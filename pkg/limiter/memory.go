@@ -0,0 +1,49 @@
+package limiter
+
+import (
+	"sync"
+	"time"
+)
+
+type MemoryStore struct {
+	mu       sync.Mutex
+	attempts map[string]Attempt
+	ttl      time.Duration
+}
+
+func NewMemoryStore(ttl time.Duration) *MemoryStore {
+	return &MemoryStore{attempts: make(map[string]Attempt), ttl: ttl}
+}
+
+func (s *MemoryStore) Load(key string) (Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.attempts[key], nil
+}
+
+func (s *MemoryStore) Update(key string, fn func(Attempt) Attempt) (Attempt, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	attempt := fn(s.attempts[key])
+	s.attempts[key] = attempt
+	s.prune(attempt.LastFailureAt)
+	return attempt, nil
+}
+
+func (s *MemoryStore) Delete(key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.attempts, key)
+	return nil
+}
+
+func (s *MemoryStore) prune(now time.Time) {
+	for key, attempt := range s.attempts {
+		if now.Sub(attempt.LastFailureAt) > s.ttl && !now.Before(attempt.LockedUntil) {
+			delete(s.attempts, key)
+		}
+	}
+}
@@ -0,0 +1,229 @@
+package signing
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/injunweb/backend-server/internal/config"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// Issuer is the iss claim of every token this service signs. Parse rejects
+// tokens carrying any other issuer.
+const Issuer = "injunweb"
+
+type key struct {
+	id         string
+	method     jwt.SigningMethod
+	privateKey crypto.PrivateKey
+	publicKey  crypto.PublicKey
+}
+
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	Alg string `json:"alg"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+}
+
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+var (
+	keys      = map[string]*key{}
+	activeKey *key
+)
+
+// Init loads every PEM file in JWT_KEYS_DIR, using the file name (without
+// extension) as the kid. Only JWT_ACTIVE_KEY_ID signs new tokens; the others stay
+// valid for verification so tokens issued before a rotation keep working until
+// they expire. Without a key directory tokens fall back to HS256 with JWT_SECRET.
+func Init() error {
+	keys = map[string]*key{}
+	activeKey = nil
+
+	if config.AppConfig.JWTKeysDir == "" {
+		log.Println("JWT_KEYS_DIR not set, signing tokens with HS256 shared secret")
+		return nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(config.AppConfig.JWTKeysDir, "*.pem"))
+	if err != nil {
+		return fmt.Errorf("failed to list signing keys: %v", err)
+	}
+
+	for _, path := range paths {
+		k, err := loadKey(path)
+		if err != nil {
+			return err
+		}
+		keys[k.id] = k
+	}
+
+	active, ok := keys[config.AppConfig.JWTActiveKeyID]
+	if !ok {
+		return fmt.Errorf("active signing key %q not found in %s", config.AppConfig.JWTActiveKeyID, config.AppConfig.JWTKeysDir)
+	}
+	if active.privateKey == nil {
+		return fmt.Errorf("active signing key %q has no private key", active.id)
+	}
+	activeKey = active
+
+	log.Printf("Loaded %d JWT signing keys, active key %s (%s)\n", len(keys), active.id, active.method.Alg())
+	return nil
+}
+
+func Sign(claims jwt.MapClaims) (string, error) {
+	claims["iss"] = Issuer
+
+	if activeKey == nil {
+		return jwt.NewWithClaims(jwt.SigningMethodHS256, claims).SignedString([]byte(config.AppConfig.JWTSecret))
+	}
+
+	token := jwt.NewWithClaims(activeKey.method, claims)
+	token.Header["kid"] = activeKey.id
+
+	return token.SignedString(activeKey.privateKey)
+}
+
+func Parse(tokenString string) (*jwt.Token, error) {
+	token, err := jwt.Parse(tokenString, keyFunc)
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !claims.VerifyIssuer(Issuer, true) {
+		return nil, fmt.Errorf("unexpected token issuer")
+	}
+
+	return token, nil
+}
+
+func keyFunc(t *jwt.Token) (interface{}, error) {
+	if activeKey == nil {
+		if _, ok := t.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+		}
+		return []byte(config.AppConfig.JWTSecret), nil
+	}
+
+	kid, _ := t.Header["kid"].(string)
+	k, ok := keys[kid]
+	if !ok {
+		return nil, fmt.Errorf("unknown signing key: %q", kid)
+	}
+
+	if t.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("unexpected signing method: %v", t.Header["alg"])
+	}
+
+	return k.publicKey, nil
+}
+
+func PublicKeySet() JSONWebKeySet {
+	ids := make([]string, 0, len(keys))
+	for id := range keys {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	set := JSONWebKeySet{Keys: []JSONWebKey{}}
+	for _, id := range ids {
+		k := keys[id]
+		jwk := JSONWebKey{Kid: k.id, Use: "sig", Alg: k.method.Alg()}
+
+		switch pub := k.publicKey.(type) {
+		case *rsa.PublicKey:
+			jwk.Kty = "RSA"
+			jwk.N = base64.RawURLEncoding.EncodeToString(pub.N.Bytes())
+			jwk.E = base64.RawURLEncoding.EncodeToString(big.NewInt(int64(pub.E)).Bytes())
+		case ed25519.PublicKey:
+			jwk.Kty = "OKP"
+			jwk.Crv = "Ed25519"
+			jwk.X = base64.RawURLEncoding.EncodeToString(pub)
+		default:
+			continue
+		}
+
+		set.Keys = append(set.Keys, jwk)
+	}
+
+	return set
+}
+
+func loadKey(path string) (*key, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key %s: %v", path, err)
+	}
+
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, fmt.Errorf("failed to decode PEM in %s", path)
+	}
+
+	k := &key{id: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))}
+
+	switch block.Type {
+	case "RSA PRIVATE KEY":
+		privateKey, err := x509.ParsePKCS1PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse RSA key %s: %v", path, err)
+		}
+		k.privateKey = privateKey
+	case "PRIVATE KEY":
+		privateKey, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse private key %s: %v", path, err)
+		}
+		k.privateKey = privateKey
+	case "PUBLIC KEY":
+		publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("failed to parse public key %s: %v", path, err)
+		}
+		k.publicKey = publicKey
+	default:
+		return nil, fmt.Errorf("unsupported PEM block %q in %s", block.Type, path)
+	}
+
+	if k.privateKey != nil {
+		switch privateKey := k.privateKey.(type) {
+		case *rsa.PrivateKey:
+			k.publicKey = &privateKey.PublicKey
+		case ed25519.PrivateKey:
+			k.publicKey = privateKey.Public()
+		default:
+			return nil, fmt.Errorf("unsupported private key type in %s", path)
+		}
+	}
+
+	switch k.publicKey.(type) {
+	case *rsa.PublicKey:
+		k.method = jwt.SigningMethodRS256
+	case ed25519.PublicKey:
+		k.method = jwt.SigningMethodEdDSA
+	default:
+		return nil, fmt.Errorf("unsupported key type in %s, expected RSA or Ed25519", path)
+	}
+
+	return k, nil
+}
@@ -0,0 +1,236 @@
+package signing
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/injunweb/backend-server/internal/config"
+
+	"github.com/golang-jwt/jwt"
+)
+
+func writeRSAKey(t *testing.T, dir string, id string) *rsa.PrivateKey {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	writePEM(t, dir, id, &pem.Block{Type: "RSA PRIVATE KEY", Bytes: x509.MarshalPKCS1PrivateKey(privateKey)})
+
+	return privateKey
+}
+
+func writeEd25519Key(t *testing.T, dir string, id string) ed25519.PrivateKey {
+	t.Helper()
+
+	_, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate Ed25519 key: %v", err)
+	}
+	der, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		t.Fatalf("failed to encode Ed25519 key: %v", err)
+	}
+	writePEM(t, dir, id, &pem.Block{Type: "PRIVATE KEY", Bytes: der})
+
+	return privateKey
+}
+
+func writePEM(t *testing.T, dir string, id string, block *pem.Block) {
+	t.Helper()
+
+	if err := os.WriteFile(filepath.Join(dir, id+".pem"), pem.EncodeToMemory(block), 0600); err != nil {
+		t.Fatalf("failed to write key %s: %v", id, err)
+	}
+}
+
+// initKeys points the package at dir with activeId as the signing key and
+// restores the previous configuration when the test ends.
+func initKeys(t *testing.T, dir string, activeId string) {
+	t.Helper()
+
+	previous := config.AppConfig
+	t.Cleanup(func() {
+		config.AppConfig = previous
+		keys = map[string]*key{}
+		activeKey = nil
+	})
+
+	config.AppConfig.JWTSecret = "test-secret"
+	config.AppConfig.JWTKeysDir = dir
+	config.AppConfig.JWTActiveKeyID = activeId
+	if err := Init(); err != nil {
+		t.Fatalf("Init returned error: %v", err)
+	}
+}
+
+func TestSignAndParse(t *testing.T) {
+	tests := []struct {
+		name    string
+		setup   func(t *testing.T, dir string) string
+		wantAlg string
+		wantKid string
+	}{
+		{
+			name:    "shared secret fallback",
+			setup:   func(t *testing.T, dir string) string { return "" },
+			wantAlg: "HS256",
+		},
+		{
+			name: "rsa",
+			setup: func(t *testing.T, dir string) string {
+				writeRSAKey(t, dir, "rsa-1")
+				return dir
+			},
+			wantAlg: "RS256",
+			wantKid: "rsa-1",
+		},
+		{
+			name: "ed25519",
+			setup: func(t *testing.T, dir string) string {
+				writeEd25519Key(t, dir, "ed-1")
+				return dir
+			},
+			wantAlg: "EdDSA",
+			wantKid: "ed-1",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			initKeys(t, tt.setup(t, t.TempDir()), tt.wantKid)
+
+			tokenString, err := Sign(jwt.MapClaims{"user_id": float64(7)})
+			if err != nil {
+				t.Fatalf("Sign returned error: %v", err)
+			}
+
+			token, err := Parse(tokenString)
+			if err != nil {
+				t.Fatalf("Parse returned error: %v", err)
+			}
+
+			if token.Method.Alg() != tt.wantAlg {
+				t.Errorf("alg = %q, want %q", token.Method.Alg(), tt.wantAlg)
+			}
+			if kid, _ := token.Header["kid"].(string); kid != tt.wantKid {
+				t.Errorf("kid = %q, want %q", kid, tt.wantKid)
+			}
+
+			claims := token.Claims.(jwt.MapClaims)
+			if claims["user_id"] != float64(7) || claims["iss"] != Issuer {
+				t.Errorf("claims = %v, want user_id 7 issued by %q", claims, Issuer)
+			}
+		})
+	}
+}
+
+func TestParseRotatedKey(t *testing.T) {
+	dir := t.TempDir()
+	writeRSAKey(t, dir, "2024-01")
+	writeEd25519Key(t, dir, "2024-06")
+
+	initKeys(t, dir, "2024-01")
+	oldToken, err := Sign(jwt.MapClaims{"user_id": float64(1)})
+	if err != nil {
+		t.Fatalf("Sign returned error: %v", err)
+	}
+
+	initKeys(t, dir, "2024-06")
+	newToken, err := Sign(jwt.MapClaims{"user_id": float64(1)})
+	if err != nil {
+		t.Fatalf("Sign returned error: %v", err)
+	}
+
+	for name, tokenString := range map[string]string{"old": oldToken, "new": newToken} {
+		if _, err := Parse(tokenString); err != nil {
+			t.Errorf("Parse of %s token returned error: %v", name, err)
+		}
+	}
+
+	token, _ := Parse(newToken)
+	if kid := token.Header["kid"]; kid != "2024-06" {
+		t.Errorf("new token kid = %v, want 2024-06", kid)
+	}
+}
+
+func TestParseRejects(t *testing.T) {
+	dir := t.TempDir()
+	privateKey := writeRSAKey(t, dir, "active")
+	initKeys(t, dir, "active")
+
+	_, unknownKey, _ := ed25519.GenerateKey(rand.Reader)
+
+	sign := func(method jwt.SigningMethod, kid string, claims jwt.MapClaims, signingKey interface{}) string {
+		token := jwt.NewWithClaims(method, claims)
+		token.Header["kid"] = kid
+		tokenString, err := token.SignedString(signingKey)
+		if err != nil {
+			t.Fatalf("failed to sign test token: %v", err)
+		}
+		return tokenString
+	}
+
+	tests := []struct {
+		name        string
+		tokenString string
+	}{
+		{"unknown kid", sign(jwt.SigningMethodEdDSA, "retired", jwt.MapClaims{"iss": Issuer}, unknownKey)},
+		{"wrong key for kid", sign(jwt.SigningMethodEdDSA, "active", jwt.MapClaims{"iss": Issuer}, unknownKey)},
+		{"shared secret with kid", sign(jwt.SigningMethodHS256, "active", jwt.MapClaims{"iss": Issuer}, []byte("test-secret"))},
+		{"missing issuer", sign(jwt.SigningMethodRS256, "active", jwt.MapClaims{}, privateKey)},
+		{"foreign issuer", sign(jwt.SigningMethodRS256, "active", jwt.MapClaims{"iss": "someone-else"}, privateKey)},
+		{"malformed", "not.a.token"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := Parse(tt.tokenString); err == nil {
+				t.Error("Parse returned no error")
+			}
+		})
+	}
+}
+
+func TestPublicKeySet(t *testing.T) {
+	dir := t.TempDir()
+	writeRSAKey(t, dir, "b-rsa")
+	writeEd25519Key(t, dir, "a-ed")
+
+	// A retired key may be kept as a public key only, for verification.
+	retired, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatalf("failed to generate RSA key: %v", err)
+	}
+	der, err := x509.MarshalPKIXPublicKey(&retired.PublicKey)
+	if err != nil {
+		t.Fatalf("failed to encode public key: %v", err)
+	}
+	writePEM(t, dir, "c-retired", &pem.Block{Type: "PUBLIC KEY", Bytes: der})
+
+	initKeys(t, dir, "b-rsa")
+
+	set := PublicKeySet()
+	want := []struct{ kid, kty, alg string }{
+		{"a-ed", "OKP", "EdDSA"},
+		{"b-rsa", "RSA", "RS256"},
+		{"c-retired", "RSA", "RS256"},
+	}
+	if len(set.Keys) != len(want) {
+		t.Fatalf("got %d keys, want %d", len(set.Keys), len(want))
+	}
+
+	for i, w := range want {
+		jwk := set.Keys[i]
+		if jwk.Kid != w.kid || jwk.Kty != w.kty || jwk.Alg != w.alg || jwk.Use != "sig" {
+			t.Errorf("key %d = %+v, want kid %s kty %s alg %s", i, jwk, w.kid, w.kty, w.alg)
+		}
+	}
+}
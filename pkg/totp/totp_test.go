@@ -0,0 +1,130 @@
+package totp
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+// rfcSecret is the SHA1 seed "12345678901234567890" from RFC 6238 Appendix B.
+const rfcSecret = "GEZDGNBVGY3TQOJQGEZDGNBVGY3TQOJQ"
+
+func TestGenerateCodeRFC6238(t *testing.T) {
+	// Appendix B lists 8-digit codes; a 6-digit code is the same value
+	// truncated to its last six digits.
+	tests := []struct {
+		unix int64
+		want string
+	}{
+		{59, "287082"},
+		{1111111109, "081804"},
+		{1111111111, "050471"},
+		{1234567890, "005924"},
+		{2000000000, "279037"},
+		{20000000000, "353130"},
+	}
+
+	for _, tt := range tests {
+		got, err := GenerateCode(rfcSecret, time.Unix(tt.unix, 0))
+		if err != nil {
+			t.Fatalf("GenerateCode returned error: %v", err)
+		}
+		if got != tt.want {
+			t.Errorf("GenerateCode at %d = %s, want %s", tt.unix, got, tt.want)
+		}
+	}
+}
+
+func TestValidateWindow(t *testing.T) {
+	now := time.Unix(1111111111, 0)
+	current := now.Unix() / period
+
+	tests := []struct {
+		name     string
+		codeAt   time.Time
+		wantOK   bool
+		wantStep int64
+	}{
+		{"current step", now, true, current},
+		{"previous step", now.Add(-period * time.Second), true, current - 1},
+		{"next step", now.Add(period * time.Second), true, current + 1},
+		{"two steps old", now.Add(-2 * period * time.Second), false, 0},
+		{"two steps ahead", now.Add(2 * period * time.Second), false, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			code, err := GenerateCode(rfcSecret, tt.codeAt)
+			if err != nil {
+				t.Fatalf("GenerateCode returned error: %v", err)
+			}
+
+			step, ok := Validate(rfcSecret, code, now, 0)
+			if ok != tt.wantOK || step != tt.wantStep {
+				t.Errorf("Validate = (%d, %v), want (%d, %v)", step, ok, tt.wantStep, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestValidateRejectsReplay(t *testing.T) {
+	now := time.Unix(1234567890, 0)
+	code, err := GenerateCode(rfcSecret, now)
+	if err != nil {
+		t.Fatalf("GenerateCode returned error: %v", err)
+	}
+
+	step, ok := Validate(rfcSecret, code, now, 0)
+	if !ok {
+		t.Fatal("first use of the code was rejected")
+	}
+
+	if _, ok := Validate(rfcSecret, code, now, step); ok {
+		t.Error("replayed code was accepted in the same step")
+	}
+	if _, ok := Validate(rfcSecret, code, now.Add(period*time.Second), step); ok {
+		t.Error("replayed code was accepted in the next step")
+	}
+
+	previous, err := GenerateCode(rfcSecret, now.Add(-period*time.Second))
+	if err != nil {
+		t.Fatalf("GenerateCode returned error: %v", err)
+	}
+	if _, ok := Validate(rfcSecret, previous, now, step); ok {
+		t.Error("code older than the last accepted step was accepted")
+	}
+
+	next, err := GenerateCode(rfcSecret, now.Add(period*time.Second))
+	if err != nil {
+		t.Fatalf("GenerateCode returned error: %v", err)
+	}
+	if nextStep, ok := Validate(rfcSecret, next, now.Add(period*time.Second), step); !ok || nextStep != step+1 {
+		t.Errorf("code of the following step = (%d, %v), want (%d, true)", nextStep, ok, step+1)
+	}
+}
+
+func TestValidateRejectsMalformedCodes(t *testing.T) {
+	now := time.Unix(59, 0)
+
+	tests := []struct {
+		name string
+		code string
+	}{
+		{"empty", ""},
+		{"too short", "28708"},
+		{"rfc 8 digit code", "94287082"},
+		{"wrong code", "287083"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, ok := Validate(rfcSecret, tt.code, now, 0); ok {
+				t.Errorf("Validate(%q) accepted the code", tt.code)
+			}
+		})
+	}
+
+	if _, ok := Validate(strings.ToLower(rfcSecret), " 287082 ", now, 0); !ok {
+		t.Error("Validate rejected a padded code with a lower-case secret")
+	}
+}
@@ -3,6 +3,8 @@ package validator
 import (
 	"regexp"
 	"strings"
+
+	"github.com/injunweb/backend-server/internal/config"
 )
 
 var (
@@ -12,6 +14,13 @@ var (
 		"SELECT", "INSERT", "UPDATE", "DELETE",
 		"DROP", "EXEC", "UNION", "OR", "AND",
 	}
+	// Application names become namespace, database, Vault and Harbor names,
+	// so they must not collide with anything the cluster or platform owns.
+	reservedNames = []string{
+		"default", "argocd", "vault", "harbor", "mysql", "sys", "root",
+		"cert-manager", "ingress-nginx", "monitoring", "injunweb",
+	}
+	reservedPrefixes = []string{"kube-"}
 )
 
 func IsValidApplicationName(name string) bool {
@@ -25,5 +34,26 @@ func IsValidApplicationName(name string) bool {
 			return false
 		}
 	}
-	return true
+
+	return !isReservedName(name)
+}
+
+func isReservedName(name string) bool {
+	if name == config.AppConfig.DBName || name == config.AppConfig.DBUser {
+		return true
+	}
+
+	for _, reserved := range reservedNames {
+		if name == reserved {
+			return true
+		}
+	}
+
+	for _, prefix := range reservedPrefixes {
+		if strings.HasPrefix(name, prefix) {
+			return true
+		}
+	}
+
+	return false
 }
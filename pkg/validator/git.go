@@ -0,0 +1,50 @@
+package validator
+
+import (
+	"net/url"
+	"regexp"
+	"strings"
+)
+
+var (
+	gitPathRegex   = regexp.MustCompile(`^/[A-Za-z0-9_.\-]+/[A-Za-z0-9_.\-]+(\.git)?/?$`)
+	branchRegex    = regexp.MustCompile(`^[A-Za-z0-9._/\-]+$`)
+	branchBadParts = []string{"..", "//", "@{", "/.", ".lock/"}
+)
+
+func IsValidGitURL(gitURL string) bool {
+	if len(gitURL) > 255 {
+		return false
+	}
+
+	u, err := url.Parse(gitURL)
+	if err != nil || u.Scheme != "https" || u.User != nil || u.RawQuery != "" || u.Fragment != "" {
+		return false
+	}
+
+	return IsValidHostname(u.Hostname()) && gitPathRegex.MatchString(u.Path)
+}
+
+// IsValidBranch applies the subset of git check-ref-format rules that matter
+// for branch names passed through to the GitOps workflow.
+func IsValidBranch(branch string) bool {
+	if branch == "" || len(branch) > 255 || !branchRegex.MatchString(branch) {
+		return false
+	}
+
+	if strings.HasPrefix(branch, "/") || strings.HasPrefix(branch, "-") || strings.HasPrefix(branch, ".") {
+		return false
+	}
+
+	if strings.HasSuffix(branch, "/") || strings.HasSuffix(branch, ".") || strings.HasSuffix(branch, ".lock") {
+		return false
+	}
+
+	for _, part := range branchBadParts {
+		if strings.Contains(branch+"/", part) {
+			return false
+		}
+	}
+
+	return true
+}
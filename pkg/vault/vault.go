@@ -2,6 +2,7 @@ package vault
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/injunweb/backend-server/internal/config"
@@ -64,3 +65,15 @@ func DeleteSecret(path string) error {
 
 	return nil
 }
+
+func SecretExists(path string) (bool, error) {
+	_, err := client.KVv1(config.AppConfig.VaultKV).Get(ctx, path)
+	if errors.Is(err, api.ErrSecretNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to read from Vault: %v", err)
+	}
+
+	return true, nil
+}